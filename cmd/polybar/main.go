@@ -1,7 +1,11 @@
 package main
 
 import (
+    "context"
     "flag"
+    "fmt"
+    "os"
+    "os/signal"
     "time"
     "github.com/William-Gardner-Biotech/polybar/polybar"
 )
@@ -13,12 +17,20 @@ func main() {
     interval  := flag.Duration("interval", 50*time.Millisecond, "Delay between Update() calls")
 
     flag.Parse()
-    pb := polybar.New(*seqPtr, *headerPtr)
-    pb.Start(*totalPtr)
+    ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer cancel()
 
-    for i := 0; i < *totalPtr; i++ {
-        time.Sleep(*interval)
-        pb.Update()
+    pb := polybar.New(*seqPtr, *headerPtr)
+    err := pb.RunWithContext(ctx, *totalPtr, func(int) error {
+        select {
+        case <-time.After(*interval):
+            return nil
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+    })
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
     }
-    pb.Finish()
 }
@@ -30,4 +30,17 @@ func main() {
 		pb2.SetProgress((i + 1) * 10)
 	}
 	pb2.Finish()
-}
+
+	time.Sleep(1 * time.Second)
+
+	// Example 3: Random (but reproducible) sequence
+	println("\nExample 3: Random-sequence progress bar")
+	pb3 := polybar.New(polybar.RandomSequence(30, 42), "")
+	pb3.Start(30)
+
+	for i := 0; i < 30; i++ {
+		time.Sleep(100 * time.Millisecond)
+		pb3.Update()
+	}
+	pb3.Finish()
+}
\ No newline at end of file
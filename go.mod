@@ -1,3 +1,7 @@
 module github.com/William-Gardner-Biotech/polybar
 
 go 1.21
+
+require golang.org/x/term v0.15.0
+
+require golang.org/x/sys v0.15.0
@@ -0,0 +1,63 @@
+package polybar
+
+import (
+	"errors"
+	"time"
+)
+
+// StartAuto starts the bar like Start and then redraws it every refresh
+// interval from a background goroutine, so elapsed time and ETA keep
+// moving even when progress is reported rarely. Each redraw shows whatever
+// progress is current. The goroutine exits on Finish, Abort, Stop or the
+// next Start.
+func (pb *ProgressBar) StartAuto(total int, refresh time.Duration) error {
+	if refresh <= 0 {
+		return errors.New("polybar: refresh interval must be positive")
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if err := pb.begin(total); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	pb.auto = stop
+	go pb.autoRefresh(stop, refresh)
+	return nil
+}
+
+// Stop ends auto-refresh started by StartAuto, leaving the bar as it is.
+// It is a no-op if auto-refresh is not running.
+func (pb *ProgressBar) Stop() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.stopAuto()
+}
+
+// autoRefresh redraws the bar on every tick until stop is closed.
+func (pb *ProgressBar) autoRefresh(stop chan struct{}, refresh time.Duration) {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			pb.mu.Lock()
+			// The loop may have been stopped while waiting for the lock.
+			if pb.auto == stop {
+				pb.render()
+			}
+			pb.mu.Unlock()
+		}
+	}
+}
+
+// stopAuto signals the auto-refresh goroutine, if any, to exit. It does
+// not wait, so it is safe to call with pb.mu held.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) stopAuto() {
+	if pb.auto != nil {
+		close(pb.auto)
+		pb.auto = nil
+	}
+}
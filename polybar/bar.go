@@ -0,0 +1,18 @@
+package polybar
+
+// Bar is the progress-reporting surface of a ProgressBar. Code that only
+// reports progress can depend on Bar instead of *ProgressBar, so tests can
+// pass in a no-op or recording double.
+type Bar interface {
+	Start(total int) error
+	Update()
+	Add(n int)
+	SetProgress(completed int)
+	Finish()
+	Abort(reason string)
+	Percent() float64
+	Completed() int
+	Total() int
+}
+
+var _ Bar = (*ProgressBar)(nil)
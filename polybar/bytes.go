@@ -0,0 +1,54 @@
+package polybar
+
+import (
+	"fmt"
+	"math"
+)
+
+// StartBytes starts a bar that tracks a byte count, e.g. a file copy.
+// Progress is reported with AddBytes (Update, Add and SetProgress are
+// ignored) and the status line shows humanized sizes, e.g. "40.0% (1.2
+// GB/3.0 GB)". Counts are kept as int64, so files larger than an int can
+// hold on 32-bit platforms are fine. It returns an error, and draws
+// nothing, if totalBytes is not positive.
+func (pb *ProgressBar) StartBytes(totalBytes int64) error {
+	if totalBytes <= 0 {
+		return fmt.Errorf("polybar: total bytes must be positive, got %d", totalBytes)
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if err := pb.restart(weightSteps); err != nil {
+		return err
+	}
+	pb.bytesTotal = totalBytes
+	pb.bytesDone = 0
+	pb.progressed()
+	pb.render()
+	return nil
+}
+
+// AddBytes adds n to the bytes completed and refreshes. The count is
+// clamped to [0, totalBytes]. It does nothing unless the bar was started
+// with StartBytes.
+func (pb *ProgressBar) AddBytes(n int64) {
+	pb.mu.Lock()
+	if pb.bytesTotal == 0 {
+		pb.mu.Unlock()
+		return
+	}
+	// Clamp without computing bytesDone+n, which could overflow.
+	switch {
+	case n > pb.bytesTotal-pb.bytesDone:
+		pb.bytesDone = pb.bytesTotal
+	case n < -pb.bytesDone:
+		pb.bytesDone = 0
+	default:
+		pb.bytesDone += n
+	}
+	pb.completed = int(math.Round(float64(pb.bytesDone) / float64(pb.bytesTotal) * weightSteps))
+	pb.progressed()
+	pb.render()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
+}
@@ -0,0 +1,47 @@
+package polybar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStepMethodsLeaveWeightedAndByteRunsAlone(t *testing.T) {
+	runs := []struct {
+		name      string
+		start     func(pb *ProgressBar)
+		completed int
+		status    string
+	}{
+		{"weighted", func(pb *ProgressBar) {
+			pb.StartWeighted(6.25)
+			pb.AddWeight(2.5)
+		}, 4000, "40.0% (2.5/6.25)"},
+		{"bytes", func(pb *ProgressBar) {
+			pb.StartBytes(4096)
+			pb.AddBytes(1024)
+		}, 2500, "25.0% (1.0 KB/4.0 KB)"},
+	}
+	steps := []struct {
+		name string
+		step func(pb *ProgressBar)
+	}{
+		{"Update", func(pb *ProgressBar) { pb.Update() }},
+		{"Add", func(pb *ProgressBar) { pb.Add(500) }},
+		{"SetProgress", func(pb *ProgressBar) { pb.SetProgress(5000) }},
+	}
+	for _, run := range runs {
+		for _, st := range steps {
+			t.Run(run.name+"/"+st.name, func(t *testing.T) {
+				pb := New("ACGT", "", WithQuiet(true))
+				run.start(pb)
+				st.step(pb)
+				if got := pb.Completed(); got != run.completed {
+					t.Errorf("Completed() = %d, want %d", got, run.completed)
+				}
+				if frame := pb.Frame(); !strings.Contains(frame, run.status) {
+					t.Errorf("frame %q does not show %s", frame, run.status)
+				}
+			})
+		}
+	}
+}
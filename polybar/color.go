@@ -0,0 +1,106 @@
+package polybar
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	ansiReset   = "\033[0m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiBlue    = "\033[34m"
+	ansiReverse = "\033[7m"
+)
+
+// baseColors maps each nucleotide to the ANSI color it is drawn in,
+// regardless of case. Bases not listed are printed uncolored.
+var baseColors = map[rune]string{
+	'A': ansiGreen,
+	'T': ansiRed,
+	'U': ansiRed,
+	'G': ansiYellow,
+	'C': ansiBlue,
+	'N': ansiDim,
+	'-': ansiDim,
+}
+
+// colorEnabled reports whether this frame should be colored: WithColor or
+// WithHighlightNewBase was given, the output is a terminal and NO_COLOR is
+// not set. Callers must hold pb.mu.
+func (pb *ProgressBar) colorEnabled() bool {
+	return (pb.color || pb.highlightNew) && pb.tty && os.Getenv("NO_COLOR") == ""
+}
+
+// palette returns how to color each base of this frame: nil when color is
+// off, the WithBaseColorFunc callback if one was given, the fixed
+// baseColors palette under WithColor, or no color at all. With
+// WithHighlightNewBase the base added by the last step is also shown in
+// reverse video, except in the paired-end, hairpin and antiparallel
+// layouts. Callers must hold pb.mu.
+func (pb *ProgressBar) palette(color bool) func(index int, base rune) string {
+	if !color {
+		return nil
+	}
+	colorOf := func(int, rune) string { return "" }
+	switch {
+	case pb.baseColor != nil:
+		colorOf = pb.baseColor
+	case pb.color:
+		colorOf = func(_ int, base rune) string { return baseColors[unicode.ToUpper(base)] }
+	}
+	if !pb.highlightNew || !pb.grew || pb.pairedEnd || pb.hairpin || pb.antiparallel {
+		return colorOf
+	}
+	newest := pb.lastHi - 1
+	return func(index int, base rune) string {
+		if index == newest {
+			return ansiReverse + colorOf(index, base)
+		}
+		return colorOf(index, base)
+	}
+}
+
+// paint returns bases as a string, wrapping each one in the ANSI prefix
+// colorOf gives it, where bases[0] is at column from of the strand. A nil
+// colorOf or an empty prefix leaves a base uncolored. The escapes add no
+// visible width.
+func paint(bases []rune, from int, colorOf func(index int, base rune) string) string {
+	if colorOf == nil {
+		return string(bases)
+	}
+	var b strings.Builder
+	for i, r := range bases {
+		code := colorOf(from+i, r)
+		if code == "" {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(code)
+		b.WriteRune(r)
+		b.WriteString(ansiReset)
+	}
+	return b.String()
+}
+
+// plainWidth returns how many runes of s show on screen, not counting ANSI
+// escape sequences such as those added by paint.
+func plainWidth(s string) int {
+	n := 0
+	esc := false
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			esc = true
+		case esc:
+			// A CSI sequence ends with a letter; '[' only opens it.
+			esc = r == '[' || r < '@' || r > '~'
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package polybar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHighlightNewBase(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	tests := []struct {
+		name string
+		opts []Option
+		want int // highlighted bases in the frame
+	}{
+		{"straight duplex", nil, 2},
+		{"antiparallel", []Option{WithAntiparallelFill()}, 0},
+		{"paired end", []Option{WithPairedEnd(0)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := append([]Option{WithForceTTY(true), WithHighlightNewBase()}, tt.opts...)
+			pb := New("AACCGGTTAC", "", opts...)
+			pb.SetOutput(&out)
+			pb.Start(10)
+			pb.SetProgress(4)
+			out.Reset()
+			pb.SetProgress(6)
+			if got := strings.Count(out.String(), ansiReverse); got != tt.want {
+				t.Errorf("%d bases highlighted, want %d: %q", got, tt.want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package polybar
+
+import "encoding/json"
+
+// Event is the JSON object written to the WithEventWriter stream each time
+// progress changes. For a StartWeighted or StartBytes run, Completed and
+// Total are the internal step scale and the weights or byte counts are
+// reported alongside.
+type Event struct {
+	Completed   int     `json:"completed"`
+	Total       int     `json:"total"`
+	Percent     float64 `json:"percent"`
+	ElapsedMS   int64   `json:"elapsed_ms"`
+	WeightDone  float64 `json:"weight_done,omitempty"`
+	WeightTotal float64 `json:"weight_total,omitempty"`
+	BytesDone   int64   `json:"bytes_done,omitempty"`
+	BytesTotal  int64   `json:"bytes_total,omitempty"`
+}
+
+// emit writes the current progress to the event writer as one line of
+// JSON. Write errors are ignored so a broken consumer can't stall the bar.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) emit() {
+	if pb.events == nil {
+		return
+	}
+	_ = json.NewEncoder(pb.events).Encode(Event{
+		Completed:   pb.completed,
+		Total:       pb.total,
+		Percent:     pb.percent(),
+		ElapsedMS:   pb.elapsed().Milliseconds(),
+		WeightDone:  pb.weightDone,
+		WeightTotal: pb.weightTotal,
+		BytesDone:   pb.bytesDone,
+		BytesTotal:  pb.bytesTotal,
+	})
+}
@@ -0,0 +1,67 @@
+package polybar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewFromFASTA creates a bar whose top strand is the first record of the
+// FASTA file at path. The record's ">" description line is skipped and its
+// wrapped sequence lines are joined. header and opts are as for New.
+func NewFromFASTA(path, header string, opts ...Option) (*ProgressBar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("polybar: %w", err)
+	}
+	defer f.Close()
+
+	seq, err := readFASTA(f)
+	if err != nil {
+		return nil, fmt.Errorf("polybar: %s: %w", path, err)
+	}
+	return New(seq, header, opts...), nil
+}
+
+// readFASTA returns the sequence of the first record in r. Blank lines and
+// anything before the first ">" line are ignored; reading stops at the
+// next ">" line.
+func readFASTA(r io.Reader) (string, error) {
+	br := bufio.NewReader(r)
+	var seq strings.Builder
+	inRecord := false
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, ">"):
+			if inRecord {
+				return finishFASTA(seq.String())
+			}
+			inRecord = true
+		case inRecord:
+			seq.WriteString(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if !inRecord {
+		return "", errors.New("no FASTA record found")
+	}
+	return finishFASTA(seq.String())
+}
+
+// finishFASTA rejects a record with no sequence lines.
+func finishFASTA(seq string) (string, error) {
+	if seq == "" {
+		return "", errors.New("first FASTA record is empty")
+	}
+	return seq, nil
+}
@@ -0,0 +1,87 @@
+package polybar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Group draws several bars together, one below another, so they don't
+// clobber each other's frames. Bars added to a group stop drawing on their
+// own; update them as usual and call Render to redraw the whole stack in
+// one pass.
+type Group struct {
+	mu    sync.Mutex
+	out   io.Writer
+	tty   bool
+	bars  []*ProgressBar
+	lines int // lines written by the previous Render, to move back over
+}
+
+// NewGroup creates a group drawing to out, or to os.Stderr if out is nil.
+func NewGroup(out io.Writer) *Group {
+	if out == nil {
+		out = os.Stderr
+	}
+	f, ok := out.(*os.File)
+	return &Group{out: out, tty: ok && term.IsTerminal(int(f.Fd()))}
+}
+
+// Add registers bars with the group, below any already added.
+func (g *Group) Add(bars ...*ProgressBar) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, pb := range bars {
+		pb.mu.Lock()
+		pb.grouped = true
+		pb.mu.Unlock()
+		g.bars = append(g.bars, pb)
+	}
+}
+
+// Render redraws every bar in the group, overwriting the previous stack.
+// When the output is not a terminal nothing is drawn until Finish.
+func (g *Group) Render() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.tty {
+		return
+	}
+	g.draw()
+}
+
+// Finish draws the final stack and moves the cursor below it.
+func (g *Group) Finish() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.draw()
+	fmt.Fprintln(g.out)
+}
+
+// draw writes all bars' current frames as a single block.
+// Callers must hold g.mu.
+func (g *Group) draw() {
+	var lines []string
+	for _, pb := range g.bars {
+		pb.mu.Lock()
+		lines = append(lines, pb.frameLines(pb.color && g.tty && os.Getenv("NO_COLOR") == "")...)
+		pb.mu.Unlock()
+	}
+
+	if g.tty {
+		enableVirtualTerminal(g.out)
+		for i := 0; i < g.lines; i++ {
+			fmt.Fprint(g.out, "\033[F")
+		}
+	}
+	for _, line := range lines {
+		if g.tty {
+			line += "\033[K"
+		}
+		fmt.Fprintln(g.out, line)
+	}
+	g.lines = len(lines)
+}
@@ -0,0 +1,70 @@
+package polybar
+
+import (
+	"strings"
+	"unicode"
+)
+
+// selfComplementary reports whether seq reads the same as its reverse
+// complement under pairs, ignoring case, e.g. GAATTC. Sequences shorter
+// than two bases, or with gaps or N, never count.
+func selfComplementary(seq []rune, pairs map[rune]rune) bool {
+	if len(seq) < 2 {
+		return false
+	}
+	rc := generateComplement(seq, pairs)
+	reverseRunes(rc)
+	for i, r := range seq {
+		switch unicode.ToUpper(r) {
+		case '-', 'N':
+			return false
+		}
+		if unicode.ToUpper(r) != unicode.ToUpper(rc[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// hairpinLines builds the frame for WithHairpinDetection: the sequence
+// folded in half, its first half 5′→3′ on the top line and its second
+// half running back underneath, paired base for base, with the loop at
+// the right. Both halves grow toward the loop as progress is made. An odd
+// middle base sits in the loop. The phase, ruler and amino-acid lines are
+// left blank, and WithAutoWidth and WithMaxWidth windows do not apply.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) hairpinLines(color bool) []string {
+	seq := pb.topStrand[pb.seqLo:pb.seqHi]
+	half := len(seq) / 2
+	n := pb.fill() * half / pb.width
+	if pb.indeterminate {
+		n = pb.completed % (half + 1)
+	}
+	back := make([]rune, half)
+	for i := range back {
+		back[i] = seq[len(seq)-1-i]
+	}
+	var loop string
+	if len(seq)%2 == 1 {
+		loop = string(seq[half])
+	}
+	upper, lower := "╮", "╯"
+	if pb.ascii {
+		upper, lower = `\`, "/"
+	}
+
+	pal := pb.palette(color)
+	pad := strings.Repeat(" ", half-n)
+	zipLabel, primerLabel := pb.labels()
+	lineZipper := zipLabel + strings.Repeat(pb.zipper, half)
+	lineTop := "--" + paint(seq[:n], 0, pal) + pad + upper
+	lineTicks := strings.TrimRight("  "+strings.Repeat("|", n)+pad+loop, " ")
+	lineBack := "--" + paint(back[:n], 0, pal) + pad + lower
+	linePrimer := primerLabel + strings.Repeat(pb.base, n) + pb.arrowhead()
+
+	linePercent := "(" + pb.count(pb.completed) + pb.unitSuffix() + ")"
+	if !pb.indeterminate {
+		linePercent = pb.status(color)
+	}
+	return pb.stack("", "", lineZipper, lineTop, lineTicks, lineBack, "", "", linePrimer, linePercent)
+}
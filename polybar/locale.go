@@ -0,0 +1,42 @@
+package polybar
+
+import (
+	"os"
+	"strings"
+)
+
+// ASCII stand-ins for the box-drawing glyphs, used by WithASCII and when
+// the locale can't display Unicode.
+const (
+	asciiZipper = "v"
+	asciiBase   = "^"
+)
+
+// utf8Locale reports whether the POSIX locale can display Unicode. The
+// first of LC_ALL, LC_CTYPE and LANG that is set decides; when none is
+// set (common on Windows and in containers) Unicode is assumed.
+func utf8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return true
+}
+
+// useASCII swaps the default zipper and base glyphs for ASCII stand-ins
+// when WithASCII is set or the locale is not UTF-8. Glyphs chosen with
+// WithZipperChar, WithBaseChar or WithStyle are left alone.
+func (pb *ProgressBar) useASCII() {
+	if !pb.ascii && utf8Locale() {
+		return
+	}
+	pb.ascii = true
+	if pb.zipper == zipperChar {
+		pb.zipper = asciiZipper
+	}
+	if pb.base == baseChar {
+		pb.base = asciiBase
+	}
+}
@@ -0,0 +1,57 @@
+package polybar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LogWriter returns a writer for log output that shares the terminal with
+// the bar, e.g. log.SetOutput(pb.LogWriter()). Each Write erases the bar,
+// prints the bytes where it was and draws the bar again below them, so the
+// bar stays pinned under the scrolling log. When the bar is not drawn in
+// place (off a terminal, or after Finish) writes go straight to the output.
+// Log output is never dropped: under WithWriteTimeout, a write first waits
+// for any frame still being written.
+func (pb *ProgressBar) LogWriter() io.Writer {
+	return &logWriter{pb: pb}
+}
+
+// logWriter interleaves log lines with the bar's frames.
+type logWriter struct {
+	pb *ProgressBar
+}
+
+func (l *logWriter) Write(b []byte) (int, error) {
+	pb := l.pb
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	// Log lines must not be dropped or interleaved with a slow frame, so
+	// let any frame still being written finish first.
+	pb.settle()
+	pinned := pb.tty && pb.drawn > 0
+	if pinned {
+		switch {
+		case pb.crMode():
+			fmt.Fprint(pb.out, pb.crErase())
+		case pb.anchor:
+			fmt.Fprint(pb.out, "\033[u\033[J")
+		default:
+			for i := 0; i < pb.drawn; i++ {
+				fmt.Fprint(pb.out, "\033[F")
+			}
+			fmt.Fprint(pb.out, "\033[J")
+		}
+		pb.drawn = 0
+	}
+	n, err := pb.out.Write(b)
+	if pinned {
+		// The bar must start on a line of its own.
+		if !bytes.HasSuffix(b, []byte("\n")) {
+			fmt.Fprintln(pb.out)
+		}
+		pb.draw()
+	}
+	return n, err
+}
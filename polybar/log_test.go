@@ -0,0 +1,35 @@
+package polybar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowWriter is a bytes.Buffer that takes a while to accept each write.
+// It is not safe for concurrent use, so the race detector catches two
+// writes that overlap.
+type slowWriter struct {
+	bytes.Buffer
+}
+
+func (s *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	return s.Buffer.Write(p)
+}
+
+func TestLogWriterWaitsForTimedOutFrame(t *testing.T) {
+	out := &slowWriter{}
+	pb := New("ACGT", "", WithForceTTY(true), WithWriteTimeout(time.Millisecond))
+	pb.SetOutput(out)
+	pb.Start(4)
+	pb.Update()
+	fmt.Fprintln(pb.LogWriter(), "log line")
+	pb.Finish()
+	fmt.Fprint(pb.LogWriter(), "") // wait for the final frame
+	if !strings.Contains(out.String(), "log line\n") {
+		t.Errorf("log line missing from output %q", out.String())
+	}
+}
@@ -0,0 +1,27 @@
+package polybar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// milestones writes a line to the milestone writer for every multiple of
+// the milestone step that progress has crossed since the last call, e.g.
+// "align: 20% (24/120)", with the counts shown as on the status line.
+// Each milestone of a run is written once, however progress jumps about.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) milestones() {
+	if pb.milestoneOut == nil || pb.indeterminate || pb.total == 0 {
+		return
+	}
+	reached := int(math.Floor(pb.percent()/pb.milestoneStep + 1e-9))
+	for ; pb.milestone < reached; pb.milestone++ {
+		pct := float64(pb.milestone+1) * pb.milestoneStep
+		line := fmt.Sprintf("%s%% (%s)", strconv.FormatFloat(pct, 'f', -1, 64), pb.counts())
+		if pb.headerLine != "" {
+			line = pb.headerLine + ": " + line
+		}
+		fmt.Fprintln(pb.milestoneOut, pb.stamp()+line)
+	}
+}
@@ -0,0 +1,635 @@
+package polybar
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Option configures a ProgressBar at construction time. Pass any number of
+// options to New; with none, New behaves exactly as it always has.
+type Option func(*ProgressBar)
+
+// WithZipperChar replaces the ┬ glyph drawn across the zipper line.
+func WithZipperChar(r rune) Option {
+	return func(pb *ProgressBar) {
+		pb.zipper = string(r)
+	}
+}
+
+// WithBaseChar replaces the ┴ glyph drawn along the primer line.
+func WithBaseChar(r rune) Option {
+	return func(pb *ProgressBar) {
+		pb.base = string(r)
+	}
+}
+
+// WithArrow replaces the "===>" arrowhead at the end of the primer.
+func WithArrow(s string) Option {
+	return func(pb *ProgressBar) {
+		pb.arrow = s
+	}
+}
+
+// WithFinishArrow replaces the arrowhead once progress reaches total. By
+// default the "===>" arrow becomes "===|" at completion, while a custom
+// WithArrow arrow is left as it is; use this to pick, say, "<==>" or "✓".
+func WithFinishArrow(s string) Option {
+	return func(pb *ProgressBar) {
+		pb.finishArrow = &s
+	}
+}
+
+// Style is a set of glyphs for the duplex art: the zipper and base runes
+// and the primer's arrowhead, as set individually by WithZipperChar,
+// WithBaseChar and WithArrow.
+type Style struct {
+	Zipper rune
+	Base   rune
+	Arrow  string
+}
+
+// Ready-made styles for WithStyle.
+var (
+	StyleLadder = Style{Zipper: '┬', Base: '┴', Arrow: "===>"} // the default
+	StyleHelix  = Style{Zipper: '∿', Base: '≈', Arrow: "~~>"}
+	StyleBlocks = Style{Zipper: '░', Base: '█', Arrow: ""} // a classic filled bar
+)
+
+// WithStyle sets the zipper, base and arrow glyphs together from s, e.g.
+// StyleBlocks. Options given after it can still override single glyphs.
+func WithStyle(s Style) Option {
+	return func(pb *ProgressBar) {
+		pb.zipper = string(s.Zipper)
+		pb.base = string(s.Base)
+		pb.arrow = s.Arrow
+	}
+}
+
+// reject notes an option value that New ignores or corrects, so that
+// NewStrict can report it. Only the first is kept.
+func (pb *ProgressBar) reject(format string, args ...any) {
+	if pb.optErr == nil {
+		pb.optErr = fmt.Errorf("polybar: "+format, args...)
+	}
+}
+
+// WithWidth fixes the number of bases across, overriding the sequence
+// length. Strands are padded (see WithPadChar and WithAlign) or truncated
+// to fit. Values below 1 are ignored by New and rejected by NewStrict.
+func WithWidth(n int) Option {
+	return func(pb *ProgressBar) {
+		if n > 0 {
+			pb.fixedWidth = n
+		} else {
+			pb.reject("width must be at least 1, got %d", n)
+		}
+	}
+}
+
+// WithMaxWidth caps how many bases are shown at once without padding
+// short sequences: the bar is as wide as the sequence, up to n. A longer
+// sequence is shown through an n-base window that slides along with the
+// fill, as WithAutoWidth does on a narrow terminal. Values below 1 are
+// ignored by New and rejected by NewStrict.
+func WithMaxWidth(n int) Option {
+	return func(pb *ProgressBar) {
+		if n > 0 {
+			pb.maxWidth = n
+		} else {
+			pb.reject("max width must be at least 1, got %d", n)
+		}
+	}
+}
+
+// Align says where a sequence narrower than the bar sits within it.
+type Align int
+
+const (
+	AlignLeft   Align = iota // sequence first, padding after (the default)
+	AlignCenter              // padding split evenly on both sides
+	AlignRight               // padding first, sequence last
+)
+
+// WithPadChar sets the rune that fills out strands narrower than the bar
+// (see WithWidth). The default is '-'.
+func WithPadChar(r rune) Option {
+	return func(pb *ProgressBar) {
+		pb.padChar = r
+	}
+}
+
+// WithAlign places a sequence narrower than the bar at its left, centre or
+// right, instead of always padding on the right. Values other than the
+// Align constants are treated as AlignLeft by New and rejected by
+// NewStrict.
+func WithAlign(a Align) Option {
+	return func(pb *ProgressBar) {
+		if a >= AlignLeft && a <= AlignRight {
+			pb.align = a
+		} else {
+			pb.reject("unknown alignment %d", a)
+		}
+	}
+}
+
+// WithRNA treats the top strand as RNA: A complements to U and U to A, so
+// the bottom strand is written with U rather than T.
+func WithRNA() Option {
+	return func(pb *ProgressBar) {
+		pb.rna = true
+	}
+}
+
+// WithForceTTY overrides terminal detection on the output writer. Forcing
+// true keeps the animated cursor-up redraws even when writing to a pipe or
+// buffer; forcing false writes a "Progress: N%" line each time the whole
+// percent rises, then the final frame.
+func WithForceTTY(tty bool) Option {
+	return func(pb *ProgressBar) {
+		pb.forceTTY = &tty
+	}
+}
+
+// WithETA appends the elapsed time and a linear estimate of the time
+// remaining to the percentage line, e.g. "elapsed=12s eta=30s".
+func WithETA() Option {
+	return func(pb *ProgressBar) {
+		pb.showETA = true
+	}
+}
+
+// WithStrandLabels replaces the end labels printed at the left of the
+// zipper (template) and primer lines, which default to 3' and 5'.
+func WithStrandLabels(top, bottom string) Option {
+	return func(pb *ProgressBar) {
+		pb.top = top
+		pb.bottom = bottom
+	}
+}
+
+// WithStrandOrder chooses which strand is drawn first, under the zipper:
+// the template (true, the default) or its complement (false), for labs
+// that expect the sense strand on top. With the complement first, the 3'
+// and 5' end labels trade places so the orientation stays correct.
+func WithStrandOrder(templateFirst bool) Option {
+	return func(pb *ProgressBar) {
+		pb.complementFirst = !templateFirst
+	}
+}
+
+// WithRuler adds a coordinate line above the zipper with a '|' under
+// every step-th base, labelled with its 1-based position where the label
+// fits, e.g. "|10       |20". Values below 1 are ignored by New and
+// rejected by NewStrict.
+func WithRuler(step int) Option {
+	return func(pb *ProgressBar) {
+		if step > 0 {
+			pb.ruler = step
+		} else {
+			pb.reject("ruler step must be at least 1, got %d", step)
+		}
+	}
+}
+
+// WithPairingTicks draws a line of '|' between the strands on the final
+// frame, one under each base that pairs with its complement, for a fully
+// zipped duplex. Gaps, N and NewDuplex mismatches get no tick. It has no
+// effect with WithReverseComplement, whose strands are not aligned pair
+// by pair.
+func WithPairingTicks() Option {
+	return func(pb *ProgressBar) {
+		pb.showTicks = true
+	}
+}
+
+// WithASCII draws the bar with ASCII only, for terminals that show the
+// box-drawing glyphs as '?' or boxes: the zipper becomes 'v', the primer
+// bases '^' and the failure marker "x FAILED". This happens automatically
+// when LC_ALL, LC_CTYPE or LANG names a locale that is not UTF-8. Glyphs
+// set explicitly with WithZipperChar, WithBaseChar or WithStyle are kept.
+func WithASCII() Option {
+	return func(pb *ProgressBar) {
+		pb.ascii = true
+	}
+}
+
+// WithColor draws each base on the strand lines in its own ANSI color
+// (A green, T/U red, G yellow, C blue, N and gaps dim). Colors are left off
+// when the output is not a terminal or the NO_COLOR environment variable is
+// set.
+func WithColor() Option {
+	return func(pb *ProgressBar) {
+		pb.color = true
+	}
+}
+
+// WithBaseColorFunc colors the strand lines with fn instead of the fixed
+// WithColor palette, e.g. to mark exons or low-quality calls. fn gets each
+// base shown on the top and complement lines with its column on the bar
+// (0 at the left end) and returns the ANSI prefix to draw it in, or "" to
+// leave it uncolored; a reset follows each colored base. It turns on
+// WithColor, so the same terminal and NO_COLOR rules apply.
+func WithBaseColorFunc(fn func(index int, base rune) string) Option {
+	return func(pb *ProgressBar) {
+		pb.baseColor = fn
+		pb.color = fn != nil || pb.color
+	}
+}
+
+// WithHighlightNewBase shows the base filled in by the latest step in
+// reverse video on the top and complement lines, so each step visibly adds
+// to the strands. It applies to the straight duplex only (not the
+// WithPairedEnd, WithHairpinDetection or WithAntiparallelFill layouts),
+// and like WithColor only on a terminal without NO_COLOR.
+func WithHighlightNewBase() Option {
+	return func(pb *ProgressBar) {
+		pb.highlightNew = true
+	}
+}
+
+// WithScroll keeps the zipper animating for runs with more steps than
+// bases: each step fills one more base and the fill wraps back to the
+// start of the sequence every width steps, until the final frame shows the
+// whole duplex. Runs with total <= width are unaffected.
+func WithScroll(scroll bool) Option {
+	return func(pb *ProgressBar) {
+		pb.scroll = scroll
+	}
+}
+
+// WithUnit labels the counts on the percentage line, e.g. "(12/40 files)"
+// or "(300/1200 bp)".
+func WithUnit(unit string) Option {
+	return func(pb *ProgressBar) {
+		pb.unit = unit
+	}
+}
+
+// WithHumanizeBytes shows the counts on the percentage line as byte sizes,
+// e.g. "(1.5 MB/3.0 GB)", for bars counting bytes such as ProxyReader.
+func WithHumanizeBytes() Option {
+	return func(pb *ProgressBar) {
+		pb.humanize = true
+	}
+}
+
+// WithShowGC appends the GC content of the sequence to the percentage line,
+// e.g. "GC=52.4%". Gaps and N are not counted.
+func WithShowGC() Option {
+	return func(pb *ProgressBar) {
+		pb.showGC = true
+	}
+}
+
+// WithShowTm appends an estimated melting temperature of the sequence to
+// the percentage line, e.g. "Tm=58°C". Up to 30 bases it uses the Wallace
+// rule, 2°C×(A+T) + 4°C×(G+C); longer sequences use the basic GC formula
+// 64.9 + 41×(G+C−16.4)/N. A sequence with no countable bases shows
+// "Tm=n/a".
+func WithShowTm() Option {
+	return func(pb *ProgressBar) {
+		pb.showTm = true
+	}
+}
+
+// WithShowCoords appends the sequence position reached to the percentage
+// line, e.g. "120/480 bp": how many bases of the sequence are filled in
+// out of its length on the bar. Unlike the step count it follows the
+// bases, so it tells which part of a long sequence the bar has reached
+// even when WithAutoWidth or WithMaxWidth shows only a window of it.
+func WithShowCoords() Option {
+	return func(pb *ProgressBar) {
+		pb.showCoords = true
+	}
+}
+
+// WithShowRemaining appends the bases left to go to the percentage line,
+// e.g. "360 bp remaining". Normally the work left is scaled to the length
+// of the sequence on the bar; with WithUnit("bp"), where each step is a
+// base, it is the exact count of steps left.
+func WithShowRemaining() Option {
+	return func(pb *ProgressBar) {
+		pb.showRemaining = true
+	}
+}
+
+// WithReverseComplement shows the bottom strand as the reverse complement,
+// read 5′→3′ like the top strand, instead of the base-for-base complement.
+// The complement line is then prefixed with 5' rather than --.
+func WithReverseComplement() Option {
+	return func(pb *ProgressBar) {
+		pb.revComp = true
+	}
+}
+
+// WithQuiet turns off all output while still tracking progress, so the
+// same code path can run silently in batch jobs.
+func WithQuiet(quiet bool) Option {
+	return func(pb *ProgressBar) {
+		pb.quiet = quiet
+	}
+}
+
+// WithTranslation adds an amino-acid track beneath the duplex, translating
+// the top strand with the standard genetic code in the given reading frame
+// (1, 2 or 3). Each one-letter code sits under the middle base of its codon
+// and stop codons show as '*'. Other frame values are ignored by New and
+// rejected by NewStrict.
+func WithTranslation(frame int) Option {
+	return func(pb *ProgressBar) {
+		if frame >= 1 && frame <= 3 {
+			pb.frame = frame
+		} else {
+			pb.reject("reading frame must be 1, 2 or 3, got %d", frame)
+		}
+	}
+}
+
+// WithPercentPrecision sets the number of decimal places shown on the
+// percentage (default 1). Negative values are clamped to 0 by New and
+// rejected by NewStrict.
+func WithPercentPrecision(n int) Option {
+	return func(pb *ProgressBar) {
+		if n < 0 {
+			pb.reject("percent precision must not be negative, got %d", n)
+			n = 0
+		}
+		pb.precision = n
+	}
+}
+
+// WithPreserveCase keeps the sequence's original case instead of
+// uppercasing it, so softmasked (lowercase) repeats stay visible. The
+// complement follows the same case: atcg pairs with tagc.
+func WithPreserveCase() Option {
+	return func(pb *ProgressBar) {
+		pb.preserveCase = true
+	}
+}
+
+// WithShowRate appends the throughput to the percentage line, e.g.
+// "12.5/s", averaged over the last few updates so it doesn't jump around.
+func WithShowRate() Option {
+	return func(pb *ProgressBar) {
+		pb.showRate = true
+	}
+}
+
+// WithCompact collapses the frame to a single line, a simple bar filled
+// with the base glyph followed by the percentage, so several bars can be
+// stacked in little vertical space.
+func WithCompact() Option {
+	return func(pb *ProgressBar) {
+		pb.compact = true
+	}
+}
+
+// WithCarriageReturn redraws a WithCompact bar by returning to the start
+// of its line with "\r" instead of moving the cursor with ANSI escapes,
+// for terminals and log viewers that drop CSI sequences but honour a
+// carriage return. Finish still ends the line with a newline. It has no
+// effect on the multi-line frame.
+func WithCarriageReturn() Option {
+	return func(pb *ProgressBar) {
+		pb.carriageReturn = true
+	}
+}
+
+// WithStatusFunc replaces the default "xx.x% (c/t)" text of the percentage
+// line with the return value of fn. Extras enabled by other options, such
+// as WithETA or WithShowGC, are still appended after it.
+func WithStatusFunc(fn StatusFunc) Option {
+	return func(pb *ProgressBar) {
+		pb.statusFunc = fn
+	}
+}
+
+// WithMinInterval throttles redraws: an update less than d after the last
+// frame was drawn changes the progress but not the screen. Finish and
+// Abort always draw the final frame. Useful when Update is called
+// thousands of times a second.
+func WithMinInterval(d time.Duration) Option {
+	return func(pb *ProgressBar) {
+		pb.minInterval = d
+	}
+}
+
+// WithWriteTimeout keeps a blocked output (a stalled pipe, a paused
+// terminal) from stalling the work being tracked: a frame write that
+// takes longer than d is left to finish in the background and the caller
+// moves on. The trade-off is that frames, including the final one, are
+// dropped for as long as that write is outstanding, so under backpressure
+// the display can lag or skip.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(pb *ProgressBar) {
+		pb.writeTimeout = d
+	}
+}
+
+// WithMilestoneWriter writes a one-line record to w, e.g. "align: 20%
+// (24/120)", each time progress crosses a multiple of every percent, as a
+// durable log alongside the transient animation. The counts are those of
+// the status line, so weighted and byte runs show weights and sizes. A
+// jump past several milestones writes each of them once. Nothing is
+// written if every is not positive, and NewStrict rejects it.
+func WithMilestoneWriter(w io.Writer, every float64) Option {
+	return func(pb *ProgressBar) {
+		if every > 0 {
+			pb.milestoneOut = w
+			pb.milestoneStep = every
+		} else {
+			pb.reject("milestone step must be positive, got %g", every)
+		}
+	}
+}
+
+// WithTimestampPrefix starts each milestone line, and each percent line
+// written when the output is not a terminal, with the current time in
+// layout (a time.Format layout such as time.RFC3339) and a space, so the
+// lines can be lined up with other logs. An empty layout turns it off.
+func WithTimestampPrefix(layout string) Option {
+	return func(pb *ProgressBar) {
+		pb.timestamp = layout
+	}
+}
+
+// WithFrameHistory keeps the last n frames, as plain text, for History,
+// e.g. to see in a test or while debugging how a redraw went wrong. Memory
+// stays bounded: older frames are dropped. Values below 1 are ignored by
+// New and rejected by NewStrict.
+func WithFrameHistory(n int) Option {
+	return func(pb *ProgressBar) {
+		if n > 0 {
+			pb.historySize = n
+		} else {
+			pb.reject("frame history must be at least 1, got %d", n)
+		}
+	}
+}
+
+// WithBellOnFinish rings the terminal bell (\a) when Finish is called, as
+// a cue for long jobs in a background tab. It does nothing off a terminal.
+func WithBellOnFinish() Option {
+	return func(pb *ProgressBar) {
+		pb.bell = true
+	}
+}
+
+// WithFinishNotification sends an OSC 9 desktop notification, "<header>:
+// done", when Finish is called. Terminals without OSC 9 support ignore it.
+// It does nothing off a terminal.
+func WithFinishNotification() Option {
+	return func(pb *ProgressBar) {
+		pb.notice = true
+	}
+}
+
+// WithEasing reshapes how the fill grows: ease maps the true fraction
+// done, in [0, 1], to the fraction of the bar to fill. Only the drawing is
+// affected; the percentage and counts stay exact. See EaseIn, EaseOut and
+// EaseInOut.
+func WithEasing(ease func(p float64) float64) Option {
+	return func(pb *ProgressBar) {
+		pb.easing = ease
+	}
+}
+
+// EaseIn starts the fill slowly and speeds it up (quadratic).
+func EaseIn(p float64) float64 {
+	return p * p
+}
+
+// EaseOut starts the fill quickly and slows it down (quadratic).
+func EaseOut(p float64) float64 {
+	return p * (2 - p)
+}
+
+// EaseInOut is slow at both ends and quickest in the middle, like a
+// replication bubble opening up (smoothstep).
+func EaseInOut(p float64) float64 {
+	return p * p * (3 - 2*p)
+}
+
+// WithPairedEnd draws paired-end reads instead of a single primer: one
+// read grows "===>" from the left end and its mate "<===" from the right,
+// meeting at total either side of an insert gap bases wide. A negative gap
+// counts as 0 in New and is rejected by NewStrict.
+func WithPairedEnd(gap int) Option {
+	return func(pb *ProgressBar) {
+		pb.pairedEnd = true
+		pb.pairedGap = max(gap, 0)
+		if gap < 0 {
+			pb.reject("paired-end gap must not be negative, got %d", gap)
+		}
+	}
+}
+
+// WithCountdown reverses the animation for deletions and rollbacks: the
+// bar starts full and the strands and primer shrink as progress is made,
+// until nothing is left at total. The percentage still counts work done;
+// use WithStatusFunc to show what remains instead.
+func WithCountdown() Option {
+	return func(pb *ProgressBar) {
+		pb.countdown = true
+	}
+}
+
+// WithReplicationFork grows the fill outward from the centre of the strands,
+// like replication proceeding both ways from an origin: the primer extends
+// with "<===" on the left and "===>" on the right.
+func WithReplicationFork() Option {
+	return func(pb *ProgressBar) {
+		pb.fork = true
+	}
+}
+
+// WithAntiparallelFill fills the complement from its right end leftward
+// while the top strand fills left to right, as the two strands of a duplex
+// run antiparallel; they meet when the bar is complete. The primer and the
+// amino-acid track follow the top strand.
+func WithAntiparallelFill() Option {
+	return func(pb *ProgressBar) {
+		pb.antiparallel = true
+	}
+}
+
+// WithAnchor pins the bar to where it was first drawn: the first frame of
+// each run saves the cursor position ("\033[s") and every later
+// frame restores it ("\033[u") before drawing, instead of moving up over
+// the previous frame. Output written between updates then cannot shift
+// the bar, though it may be drawn over. The saved position is lost if the
+// terminal scrolls, so start the bar with room below it.
+func WithAnchor() Option {
+	return func(pb *ProgressBar) {
+		pb.anchor = true
+	}
+}
+
+// WithHairpinDetection draws a self-complementary (palindromic) sequence,
+// one that reads the same as its reverse complement such as GAATTC, as a
+// hairpin: the sequence folds in half and its two halves pair up,
+// growing toward a loop on the right as progress is made. Any other
+// sequence is drawn as the usual straight duplex. It has no effect on a
+// NewDuplex bar.
+func WithHairpinDetection() Option {
+	return func(pb *ProgressBar) {
+		pb.detectHairpin = true
+	}
+}
+
+// WithOutput sends frames to w instead of os.Stderr from the start, as
+// SetOutput does later; it matters where a bar draws on construction, as
+// Restore does. A nil w keeps os.Stderr.
+func WithOutput(w io.Writer) Option {
+	return func(pb *ProgressBar) {
+		if w != nil {
+			pb.out = w
+		}
+	}
+}
+
+// WithEventWriter writes a JSON object per progress change to w, one per
+// line, e.g. {"completed":5,"total":10,"percent":50,"elapsed_ms":1200}.
+// Weighted and byte runs add weight_done and weight_total or bytes_done
+// and bytes_total. It is independent of the visual bar, so a parent process can follow
+// progress on a separate stream.
+func WithEventWriter(w io.Writer) Option {
+	return func(pb *ProgressBar) {
+		pb.events = w
+	}
+}
+
+// WithAutoWidth fits every line to the terminal's current width, checked
+// on each frame. When the strands don't fit, a window of the sequence that
+// follows the growing end of the fill is shown instead; headers and status
+// text are truncated. This keeps long lines from wrapping, which would
+// break the in-place redraw. It has no effect when the output is not a
+// terminal.
+func WithAutoWidth() Option {
+	return func(pb *ProgressBar) {
+		pb.autoWidth = true
+	}
+}
+
+// WithComplementMap replaces the base-pairing table used to build the
+// complement strand, for non-standard chemistries such as inosine. Each
+// key is complemented to its value; bases missing from m become 'N'.
+// Lowercase bases not in m are looked up uppercased and the result
+// lowercased. The map is copied, so later changes to m have no effect.
+func WithComplementMap(m map[rune]rune) Option {
+	return func(pb *ProgressBar) {
+		pb.pairs = make(map[rune]rune, len(m))
+		for k, v := range m {
+			pb.pairs[k] = v
+		}
+	}
+}
+
+// WithoutPercent leaves out the percentage line, drawing only the duplex
+// (or, in compact mode, only the bar).
+func WithoutPercent() Option {
+	return func(pb *ProgressBar) {
+		pb.noPercent = true
+	}
+}
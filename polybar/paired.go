@@ -0,0 +1,58 @@
+package polybar
+
+import "strings"
+
+// pairedLines builds the frame for WithPairedEnd: two reads growing inward
+// from either end of the strands, "===>" from the left and "<===" from the
+// right, until at total they stop either side of an insert pairedGap
+// bases wide. The amino-acid track follows the left read only. WithAutoWidth
+// and WithMaxWidth windows do not apply to this layout.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) pairedLines(color bool) []string {
+	read := (pb.width - min(pb.pairedGap, pb.width)) / 2
+	n := pb.fill() * read / pb.width
+	if pb.indeterminate {
+		n = pb.completed % (read + 1)
+	}
+	inner := pb.width - 2*n
+
+	// both shows the first and last n entries of s with the insert blank.
+	both := func(s []rune, pal func(int, rune) string) string {
+		return paint(s[:n], 0, pal) + strings.Repeat(" ", inner) + paint(s[pb.width-n:], pb.width-n, pal)
+	}
+	pal := pb.palette(color)
+
+	zipLabel, primerLabel := pb.labels()
+	lineZipper := zipLabel + strings.Repeat(pb.zipper, pb.width)
+	lineTop := strings.TrimRight("--"+both(pb.topStrand, pal), " ")
+	compPrefix := "--"
+	if pb.revComp {
+		compPrefix = "5'"
+	}
+	lineComplement := strings.TrimRight(compPrefix+both(pb.complement, pal), " ")
+
+	// The arrows share the insert; if it is too narrow for both, each is
+	// cut down to the half nearest the middle, keeping its tip.
+	left := []rune(pb.arrowhead())
+	right := []rune(mirrorArrow(pb.arrowhead()))
+	if len(left)+len(right) > inner {
+		left = left[len(left)-min(len(left), (inner+1)/2):]
+		right = right[:min(len(right), inner/2)]
+	}
+	linePrimer := primerLabel + strings.Repeat(pb.base, n) + string(left) +
+		strings.Repeat(" ", inner-len(left)-len(right)) + string(right) + strings.Repeat(pb.base, n)
+
+	linePercent := "(" + pb.count(pb.completed) + pb.unitSuffix() + ")"
+	if !pb.indeterminate {
+		linePercent = pb.status(color)
+	}
+	var lineMarks, lineProtein string
+	if pb.duplex {
+		lineMarks = strings.TrimRight(both(pb.mismatches, nil), " ")
+	}
+	if pb.frame > 0 {
+		lineProtein = translationLine(pb.protein, pb.frame, 0, n)
+	}
+
+	return pb.stack(pb.phaseLine(0, pb.width), pb.rulerLine(0, pb.width), lineZipper, lineTop, "", lineComplement, lineMarks, lineProtein, linePrimer, linePercent)
+}
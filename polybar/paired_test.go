@@ -0,0 +1,37 @@
+package polybar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPairedEnd(t *testing.T) {
+	t.Setenv("LC_ALL", "C.UTF-8") // keep the ┴ primer glyph whatever the test's locale
+	tests := []struct {
+		name        string
+		seq         string
+		gap         int
+		step        int
+		top, primer string
+	}{
+		{"empty", "ACGTACGTAC", 4, 0, "--", "5'===>  <==="},
+		{"part way", "ACGTACGTAC", 4, 5, "--A        C", "5'┴===><===┴"},
+		{"arrows cut to fit", "ACGTACGTAC", 0, 8, "--ACGT  GTAC", "5'┴┴┴┴><┴┴┴┴"},
+		{"insert at completion", "ACGTACGTAC", 4, 10, "--ACG    TAC", "5'┴┴┴=||=┴┴┴"},
+		{"no gap drops both arrows", "ACGTACGTAC", 0, 10, "--ACGTACGTAC", "5'┴┴┴┴┴┴┴┴┴┴"},
+		{"odd width", "ACGTACGTA", 3, 0, "--", "5'===> <==="},
+		{"odd width at completion", "ACGTACGTA", 0, 10, "--ACGT CGTA", "5'┴┴┴┴|┴┴┴┴"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := New(tt.seq, "", WithQuiet(true), WithPairedEnd(tt.gap))
+			lines := strings.Split(pb.CaptureFrames(10, []int{tt.step})[0], "\n")
+			if got := lines[1]; got != tt.top {
+				t.Errorf("top strand = %q, want %q", got, tt.top)
+			}
+			if got := lines[3]; got != tt.primer {
+				t.Errorf("primer = %q, want %q", got, tt.primer)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package polybar
+
+import "time"
+
+// Pause stops the clock while the job waits on something outside it, such
+// as user input: the paused time is left out of the elapsed time, the ETA
+// and the rate, and the frame on screen stays put until Resume. Progress
+// made while paused is still counted. Pause does nothing if the bar is
+// already paused or has not been started.
+func (pb *ProgressBar) Pause() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.paused() || pb.started.IsZero() {
+		return
+	}
+	pb.pausedAt = time.Now()
+}
+
+// Resume restarts the clock stopped by Pause and redraws the bar. It does
+// nothing if the bar is not paused.
+func (pb *ProgressBar) Resume() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if !pb.paused() {
+		return
+	}
+	d := time.Since(pb.pausedAt)
+	pb.started = pb.started.Add(d)
+	for i := range pb.samples {
+		pb.samples[i].at = pb.samples[i].at.Add(d)
+	}
+	pb.pausedAt = time.Time{}
+	pb.render()
+}
+
+// paused reports whether the clock is stopped by Pause.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) paused() bool {
+	return !pb.pausedAt.IsZero()
+}
+
+// now returns the current time on the bar's clock: the moment Pause was
+// called while paused, otherwise the wall clock. Callers must hold pb.mu.
+func (pb *ProgressBar) now() time.Time {
+	if pb.paused() {
+		return pb.pausedAt
+	}
+	return time.Now()
+}
+
+// elapsed returns the time since Start, paused time aside.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) elapsed() time.Duration {
+	return pb.now().Sub(pb.started)
+}
@@ -0,0 +1,92 @@
+package polybar
+
+import "strings"
+
+// phase is one labelled stage of a job, taking a share of the bar in
+// proportion to its weight.
+type phase struct {
+	name   string
+	weight float64
+}
+
+// AddPhase appends a named stage (e.g. "align", "sort", "index") taking a
+// share of the bar in proportion to weight. Once any phase is added, a
+// line above the zipper marks where each phase starts, labelled with its
+// name, and the status line names the phase progress is in. Weights that
+// are not positive are ignored.
+func (pb *ProgressBar) AddPhase(name string, weight float64) {
+	if !(weight > 0) {
+		return
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.phases = append(pb.phases, phase{name: name, weight: weight})
+	pb.render()
+}
+
+// phaseBounds returns the base at which each phase starts, plus pb.width
+// at the end. Callers must hold pb.mu.
+func (pb *ProgressBar) phaseBounds() []int {
+	var total float64
+	for _, p := range pb.phases {
+		total += p.weight
+	}
+	bounds := make([]int, len(pb.phases)+1)
+	var sum float64
+	for i, p := range pb.phases {
+		bounds[i] = int(sum / total * float64(pb.width))
+		sum += p.weight
+	}
+	bounds[len(pb.phases)] = pb.width
+	return bounds
+}
+
+// phaseLine builds the phase ruler for the bases [off, off+span): each
+// phase after the first starts with '|', followed by as much of its name
+// as fits before the next one. It is "" when no phases were added.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) phaseLine(off, span int) string {
+	if len(pb.phases) == 0 {
+		return ""
+	}
+	ruler := []rune(strings.Repeat(" ", pb.width))
+	bounds := pb.phaseBounds()
+	for i, p := range pb.phases {
+		at, end := bounds[i], bounds[i+1]
+		if i > 0 && at < end {
+			ruler[at] = '|'
+			at++
+		}
+		for _, r := range p.name {
+			if at >= end {
+				break
+			}
+			ruler[at] = r
+			at++
+		}
+	}
+	zipLabel, _ := pb.labels()
+	prefix := strings.Repeat(" ", len([]rune(zipLabel)))
+	return strings.TrimRight(prefix+string(ruler[off:off+span]), " ")
+}
+
+// currentPhase names the phase that progress is in, or "" when no phases
+// were added. Callers must hold pb.mu.
+func (pb *ProgressBar) currentPhase() string {
+	if len(pb.phases) == 0 {
+		return ""
+	}
+	var total float64
+	for _, p := range pb.phases {
+		total += p.weight
+	}
+	done := pb.percent() / 100 * total
+	var sum float64
+	for _, p := range pb.phases {
+		sum += p.weight
+		if done < sum {
+			return p.name
+		}
+	}
+	return pb.phases[len(pb.phases)-1].name
+}
@@ -1,198 +1,1675 @@
-// Package dnaprogress provides a DNA-style progress bar with base complementing
+// Package polybar provides a DNA-style progress bar with base complementing.
+//
+// The frame is drawn as an antiparallel duplex: the template (zipper) line
+// is labelled 3′ on the left, and the primer line is labelled 5′ on the left
+// so that it extends 5′→3′ to the right, as DNA polymerase does. Use
+// WithStrandLabels to relabel the two ends.
 package polybar
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
 const (
-	// DNA-style progress bar characters
+	// Default DNA-style progress bar characters
 	zipperChar = "┬"
 	baseChar   = "┴"
 	arrowText  = "===>"
+	finishText = "===|" // arrowText once the primer reaches the end
+
+	// Default end labels for the template (zipper) and primer lines.
+	templateLabel = "3'"
+	primerLabel   = "5'"
 
 	// Default = first 21 nt of DNA polymerase I (NCBI: NG_016798.2, positions 4972–308040)
 	defaultSequence = "GCCAGTTTTGGGCTGGTTGGC"
 )
 
-// ProgressBar represents a DNA-style progress bar
+// StatusFunc formats the main text of the percentage line from the current
+// progress and the time elapsed since Start.
+type StatusFunc func(completed, total int, percent float64, elapsed time.Duration) string
+
+// ProgressBar represents a DNA-style progress bar.
+// It is safe for concurrent use: Update, SetProgress and Finish may be
+// called from multiple goroutines and each frame is drawn without tearing.
 type ProgressBar struct {
-	width      int    // number of bases across
-	headerLine string // if non-empty, print this above zipper
-	topStrand  string // uppercase DNA (template)
-	complement string // computed complement of topStrand
-	completed  int    // how many “steps” done so far
-	total      int    // total number of “steps”
+	mu sync.Mutex // guards completed, total and output
+
+	width      int       // number of bases across
+	fixedWidth int       // explicit width from WithWidth, 0 if unset
+	optErr     error     // an option value New ignored, reported by NewStrict
+	headerLine string    // if non-empty, print this above zipper
+	sequence   string    // DNA as given (before case folding and padding)
+	topStrand  []rune    // template, padded/truncated to width
+	complement []rune    // computed complement of topStrand
+	completed  int       // how many “steps” done so far
+	total      int       // total number of “steps”
+	out        io.Writer // destination for rendered frames (os.Stderr by default)
+
+	zipper string // glyph repeated across the zipper line
+	base   string // glyph repeated along the primer line
+	arrow  string // primer arrowhead
+	top    string // end label on the zipper line
+	bottom string // end label on the primer line
+	rna    bool   // complement with U instead of T
+
+	tty      bool      // whether out is an interactive terminal
+	forceTTY *bool     // overrides terminal detection when non-nil
+	vtOnce   sync.Once // enables ANSI processing on the first draw
+
+	started  time.Time // when Start was called, moved on by the time spent paused
+	pausedAt time.Time // when Pause was called, zero unless paused
+	showETA  bool      // append elapsed/ETA to the percentage line
+
+	color  bool // per-base ANSI colors requested via WithColor
+	scroll bool // wrap the fill when total exceeds width
+
+	baseColor func(index int, base rune) string // WithBaseColorFunc, nil for the default palette
+
+	gc     float64 // GC percentage of the sequence
+	showGC bool    // append GC content to the percentage line
+	tm     float64 // estimated melting temperature, NaN if unknown
+	showTm bool    // append the melting temperature to the percentage line
+
+	showCoords    bool // append the base position reached to the percentage line
+	showRemaining bool // append the bases left to go to the percentage line
+
+	revComp bool // show the bottom strand as the reverse complement
+	quiet   bool // track progress but never write anything
+
+	onComplete func() // called once when completed first reaches total
+	fired      bool   // onComplete has run for this run
+
+	done  chan struct{} // returned by Done, made on first use
+	ended bool          // Finish or Abort has been called for this run
+
+	onRender func(frame string) // called with the plain frame on every refresh
+
+	historySize int      // frames kept by WithFrameHistory, 0 if off
+	history     []string // ring of the last historySize frames
+	historyNext int      // index in history of the next frame to overwrite
+
+	frame   int    // reading frame (1-3) for the amino-acid track, 0 if off
+	protein []rune // translation of topStrand in frame
+
+	precision int // decimal places on the percentage
+
+	indeterminate bool // total unknown; animate a sliding segment instead
+	preserveCase  bool // keep lowercase (softmasked) bases as given
+
+	samples  []rateSample // recent progress for the throughput average
+	showRate bool         // append items/sec to the percentage line
+
+	duplex     bool   // bottom strand given by NewDuplex, not computed
+	bottomSeq  string // bottom strand as given to NewDuplex
+	mismatches []rune // '^' under each non-complementary position
+
+	compact        bool // draw a single line instead of the duplex
+	grouped        bool // drawn by a Group rather than on its own
+	carriageReturn bool // redraw a compact bar with "\r" instead of cursor escapes
+
+	aborted bool   // Abort was called; show the failure marker
+	reason  string // why the run was aborted
+
+	auto  chan struct{} // closed to stop the StartAuto goroutine
+	drawn int           // lines of this run's frame currently on screen
+	last  []string      // those lines, as drawn
+
+	statusFunc StatusFunc // custom percentage text, nil for the default
+
+	fork         bool // grow the fill outward from the centre
+	antiparallel bool // fill the complement from the right end
+	anchor       bool // redraw at a saved cursor position, not relative to the cursor
+
+	detectHairpin bool // fold self-complementary sequences into a hairpin
+	hairpin       bool // the sequence is self-complementary and is drawn folded
+
+	highlightNew bool // show the base added by the last step in reverse video
+	lastHi       int  // end of the filled bases after the last progress change
+	grew         bool // that change filled in more bases
+
+	events io.Writer // receives one JSON progress event per change, if set
+
+	autoWidth bool // fit lines to the terminal's current width
+
+	pairs map[rune]rune // custom complement table from WithComplementMap
+
+	noPercent bool // leave out the percentage line
+
+	final io.Writer // receives a one-line summary on Finish, if set
+
+	padChar rune  // fills strands narrower than the bar
+	align   Align // where a narrower sequence sits within the bar
+	seqLo   int   // the real bases occupy [seqLo, seqHi) of the padded strands
+	seqHi   int
+
+	countdown bool // start full and empty as progress is made
+
+	minInterval time.Duration // least time between frames drawn by render
+	lastDraw    time.Time     // when the last frame was drawn in place
+
+	finishArrow *string // arrowhead once complete, from WithFinishArrow
+
+	writeTimeout time.Duration // longest a frame write may block, 0 for no limit
+	pending      chan struct{} // closed when a timed-out write finally returns
+	buf          bytes.Buffer  // reused to build each frame's output
+
+	phases []phase // labelled stages added by AddPhase
+
+	complementFirst bool // draw the complement above the template
+
+	easing func(p float64) float64 // reshapes the fill, nil for linear
+
+	ruler int // bases between coordinate ticks, 0 for no ruler
+
+	maxWidth int // most bases shown at once from WithMaxWidth, 0 if unset
+
+	ascii bool // draw only ASCII glyphs (WithASCII or a non-UTF-8 locale)
+
+	unit     string // label after the counts on the status line, e.g. "files"
+	humanize bool   // show the counts as byte sizes
+
+	pairedEnd bool // draw two reads converging from the ends
+	pairedGap int  // bases left between the reads at total
+
+	milestoneOut  io.Writer // receives a line per milestone crossed, if set
+	milestoneStep float64   // percent between milestones
+	milestone     int       // milestones written so far this run
+	logged        int       // last whole percent logged off a terminal
+	timestamp     string    // time layout prefixed to those lines, "" for none
+
+	showTicks bool   // draw pairing ticks between the strands once complete
+	ticks     []rune // '|' under each complementary pair of the strands
+
+	weightTotal float64 // total weight of a StartWeighted run, 0 otherwise
+	weightDone  float64 // weight added so far by AddWeight
+
+	bytesTotal int64 // total bytes of a StartBytes run, 0 otherwise
+	bytesDone  int64 // bytes added so far by AddBytes
+
+	bell   bool // ring the terminal bell on Finish
+	notice bool // send an OSC 9 desktop notification on Finish
 }
 
 // New creates a new DNA progress bar.
-//   • topStrand: the DNA sequence to display (will be complemented on bottom).
-//     If empty, defaults to defaultSequence (21 nt).
-//   • header:    optional header text. If non-empty, printed above zipper;
-//                if empty, we set headerLine="" (so nothing prints there).
-func New(topStrand, header string) *ProgressBar {
-	// 1) If caller did not provide any sequence, use defaultSequence.
-	if strings.TrimSpace(topStrand) == "" {
-		topStrand = defaultSequence
-	}
-
+//   - topStrand: the DNA sequence to display (will be complemented on bottom).
+//     It is cleaned as by SanitizeSequence; if that leaves nothing, defaults
+//     to defaultSequence (21 nt).
+//   - header:    optional header text. If non-empty, printed above zipper;
+//     if empty, we set headerLine="" (so nothing prints there). The header
+//     does not affect the bar's width.
+//   - opts:      optional settings such as WithWidth or WithArrow.
+func New(topStrand, header string, opts ...Option) *ProgressBar {
 	pb := &ProgressBar{
-		topStrand:  strings.ToUpper(topStrand),
+		sequence:   topStrand,
 		completed:  0,
 		headerLine: header, // may be "" if caller wants no header
+		out:        os.Stderr,
+		zipper:     zipperChar,
+		base:       baseChar,
+		arrow:      arrowText,
+		top:        templateLabel,
+		bottom:     primerLabel,
+		precision:  1,
+		padChar:    '-',
 	}
+	for _, opt := range opts {
+		opt(pb)
+	}
+
+	// Clean up the sequence, keeping any bases a custom complement table
+	// knows about; if nothing usable is left (or the caller did not
+	// provide any), use defaultSequence.
+	pb.sequence = sanitize(pb.sequence, pb.pairs)
+	if pb.sequence == "" {
+		pb.sequence = defaultSequence
+	}
+	if pb.duplex {
+		pb.bottomSeq = sanitize(pb.bottomSeq, pb.pairs)
+	}
+
+	pb.useASCII()
+	pb.detectTTY()
+	pb.layout()
+
+	return pb
+}
+
+// SetSequence swaps in a new top strand mid-run, for example when each
+// work item is a different amplicon. The complement and width are worked
+// out again exactly as in New, and the bar is redrawn at the same
+// completed/total ratio.
+func (pb *ProgressBar) SetSequence(top string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.sequence = sanitize(top, pb.pairs)
+	if pb.sequence == "" {
+		pb.sequence = defaultSequence
+	}
+	pb.layout()
+	pb.render()
+}
 
-	// 2) Generate the complement once
-	pb.complement = generateComplement(pb.topStrand)
+// SetHeader replaces the header line mid-run, e.g. to name the current
+// stage ("Aligning" → "Sorting"). An empty s removes the header. The bar
+// is redrawn in place even though adding or removing the header changes
+// its height.
+func (pb *ProgressBar) SetHeader(s string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.headerLine = s
+	pb.render()
+}
 
-	// 3) Decide width: if header is non-empty, use its length; else use length of topStrand
-	if header != "" {
-		pb.width = len(header)
-		// Pad or truncate both strands so their printed width = len(header)
-		pb.topStrand = padOrTruncate(pb.topStrand, pb.width)
-		pb.complement = padOrTruncate(pb.complement, pb.width)
+// Width returns the number of bases across the bar.
+func (pb *ProgressBar) Width() int {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.width
+}
+
+// SetWidth changes the number of bases across after construction, as
+// WithWidth does at New: it overrides the sequence length, and the strands
+// are padded or truncated again to fit. The bar is redrawn at the same
+// progress. Values below 1 are ignored.
+func (pb *ProgressBar) SetWidth(n int) {
+	if n < 1 {
+		return
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.fixedWidth = n
+	pb.layout()
+	pb.render()
+}
+
+// NewStrict is New for tools that would rather report a mistake than have
+// it papered over. Where New falls back to defaultSequence or draws
+// unknown characters as 'N', NewStrict returns an error if:
+//   - topStrand has no bases once cleaned as by SanitizeSequence,
+//   - it contains characters that are not bases (or keys of a
+//     WithComplementMap table),
+//   - an option was given a value New would ignore or correct, such as
+//     WithWidth(0), WithTranslation(7) or WithPercentPrecision(-1).
+func NewStrict(topStrand, header string, opts ...Option) (*ProgressBar, error) {
+	pb := New(topStrand, header, opts...)
+	if pb.optErr != nil {
+		return nil, pb.optErr
+	}
+	clean, replaced := sanitizeReport(topStrand, pb.pairs)
+	if clean == "" {
+		return nil, errors.New("polybar: sequence has no bases")
+	}
+	if len(replaced) > 0 {
+		return nil, fmt.Errorf("polybar: sequence contains %d character(s) that are not bases, starting with %q", len(replaced), replaced[0])
+	}
+	return pb, nil
+}
+
+// NewDuplex creates a bar showing two explicit strands, for example a
+// primer annealed to its template, rather than computing the complement
+// of top. Positions where bottom is not the Watson-Crick complement of top
+// are flagged with '^' on a marker line beneath the duplex. It accepts the
+// same options as New.
+func NewDuplex(top, bottom string, opts ...Option) *ProgressBar {
+	withBottom := func(pb *ProgressBar) {
+		pb.duplex = true
+		pb.bottomSeq = bottom
+	}
+	return New(top, "", append([]Option{withBottom}, opts...)...)
+}
+
+// layout derives the displayed strands and width from pb.sequence.
+// Everything is measured in runes so multi-byte characters can't be split.
+func (pb *ProgressBar) layout() {
+	// 1) Generate the complement once, from the uppercased sequence unless
+	//    WithPreserveCase asked to keep softmasking.
+	if pb.preserveCase {
+		pb.topStrand = []rune(pb.sequence)
+	} else {
+		pb.topStrand = []rune(strings.ToUpper(pb.sequence))
+	}
+	pb.complement = generateComplement(pb.topStrand, pb.pairing())
+	if pb.revComp {
+		reverseRunes(pb.complement)
+	}
+	pb.gc = gcContent(pb.topStrand)
+	pb.tm = meltingTemp(pb.topStrand)
+
+	// A duplex bar shows the caller's bottom strand instead, and keeps the
+	// computed complement only to find the mismatches.
+	var expected []rune
+	if pb.duplex {
+		expected = pb.complement
+		if pb.preserveCase {
+			pb.complement = []rune(pb.bottomSeq)
+		} else {
+			pb.complement = []rune(strings.ToUpper(pb.bottomSeq))
+		}
+	}
+
+	// 2) Decide width: an explicit WithWidth wins, otherwise the whole
+	//    sequence is shown. The header has no say; it sits on its own line.
+	if pb.fixedWidth > 0 {
+		pb.width = pb.fixedWidth
 	} else {
 		pb.width = len(pb.topStrand)
-		// leave topStrand, complement as-is
 	}
 
-	return pb
+	// 3) Pad or truncate both strands so their printed width = pb.width
+	n := min(len(pb.topStrand), pb.width)
+	pb.seqLo = padStart(n, pb.width, pb.align)
+	pb.seqHi = pb.seqLo + n
+	pb.topStrand = pb.fit(pb.topStrand)
+	pb.complement = pb.fit(pb.complement)
+	if pb.duplex {
+		pb.mismatches = mismatchMarks(pb.fit(expected), pb.complement)
+	}
+	if pb.showTicks {
+		pb.ticks = pairTicks(pb.topStrand, pb.complement, pb.pairing())
+	}
+
+	pb.hairpin = pb.detectHairpin && !pb.duplex &&
+		selfComplementary(pb.topStrand[pb.seqLo:pb.seqHi], pb.pairing())
+
+	// 4) Translate the displayed template if an amino-acid track is on
+	if pb.frame > 0 {
+		pb.protein = translate(pb.topStrand, pb.frame)
+	}
+}
+
+// dnaPairs is the default complement table: Watson-Crick pairs A↔T and
+// G↔C; the '5' ↔ '3' end markers (flipped only at the termini); dash→dash.
+// IUPAC ambiguity codes pair as R↔Y, K↔M, B↔V, D↔H, while S, W and N are
+// their own complements.
+var dnaPairs = map[rune]rune{
+	'5': '3', '3': '5',
+	'A': 'T', 'T': 'A',
+	'G': 'C', 'C': 'G',
+	'R': 'Y', 'Y': 'R',
+	'K': 'M', 'M': 'K',
+	'B': 'V', 'V': 'B',
+	'D': 'H', 'H': 'D',
+	'S': 'S', 'W': 'W', 'N': 'N',
+	'-': '-',
+}
+
+// rnaPairs is dnaPairs with A pairing to U instead of T (U and T both pair
+// with A), used by WithRNA.
+var rnaPairs = func() map[rune]rune {
+	m := make(map[rune]rune, len(dnaPairs)+1)
+	for k, v := range dnaPairs {
+		m[k] = v
+	}
+	m['A'] = 'U'
+	m['U'] = 'A'
+	return m
+}()
+
+// pairing returns the complement table in effect: the WithComplementMap
+// table if one was given, otherwise the DNA or RNA default.
+func (pb *ProgressBar) pairing() map[rune]rune {
+	switch {
+	case pb.pairs != nil:
+		return pb.pairs
+	case pb.rna:
+		return rnaPairs
+	default:
+		return dnaPairs
+	}
 }
 
-// generateComplement returns the complement of a DNA sequence.
-// A↔T, G↔C; digits '5' ↔ '3'; dash→dash; others→'N'.
-func generateComplement(sequence string) string {
+// generateComplement returns the complement of a sequence by looking each
+// base up in pairs; bases not in the table become 'N'. A base is looked up
+// as given first, then uppercased, in which case the complement is
+// lowercased to match (a↔t, g↔c, ...). Digits are only end markers: a '5'
+// or '3' at either terminus is flipped, and any other digit becomes 'N'.
+func generateComplement(sequence []rune, pairs map[rune]rune) []rune {
 	complement := make([]rune, len(sequence))
 	for i, base := range sequence {
-		switch base {
-		case '5':
-			complement[i] = '3'
-		case '3':
-			complement[i] = '5'
-		case 'A':
-			complement[i] = 'T'
-		case 'T':
-			complement[i] = 'A'
-		case 'G':
-			complement[i] = 'C'
-		case 'C':
-			complement[i] = 'G'
-		case '-':
-			complement[i] = '-'
-		default:
+		terminus := i == 0 || i == len(sequence)-1
+		if unicode.IsDigit(base) && !(terminus && (base == '5' || base == '3')) {
 			complement[i] = 'N'
+			continue
+		}
+		if c, ok := pairs[base]; ok {
+			complement[i] = c
+			continue
+		}
+		upper := unicode.ToUpper(base)
+		c, ok := pairs[upper]
+		if !ok {
+			c = 'N'
+		}
+		if base != upper {
+			c = unicode.ToLower(c)
 		}
+		complement[i] = c
 	}
-	return string(complement)
+	return complement
 }
 
-// padOrTruncate returns s padded with dashes or truncated so its length == length.
-func padOrTruncate(s string, length int) string {
+// fit pads or truncates a strand to pb.width using the WithPadChar rune
+// and WithAlign placement. Callers must hold pb.mu.
+func (pb *ProgressBar) fit(s []rune) []rune {
+	return padOrTruncate(s, pb.width, pb.padChar, pb.align)
+}
+
+// padOrTruncate returns s padded with pad or truncated so its length ==
+// length. Length is counted in runes, so a multi-byte base is never split
+// and each base takes one column. A short s is placed at the left, centre
+// or right of the padding according to align; a long one always loses its
+// tail.
+func padOrTruncate(s []rune, length int, pad rune, align Align) []rune {
 	if len(s) == length {
 		return s
 	} else if len(s) < length {
-		return s + strings.Repeat("-", length-len(s))
+		padded := make([]rune, length)
+		for i := range padded {
+			padded[i] = pad
+		}
+		copy(padded[padStart(len(s), length, align):], s)
+		return padded
 	}
 	return s[:length]
 }
 
+// padStart returns where n bases start when padded out to length
+// according to align.
+func padStart(n, length int, align Align) int {
+	switch align {
+	case AlignCenter:
+		return (length - n) / 2
+	case AlignRight:
+		return length - n
+	default:
+		return 0
+	}
+}
+
+// SetOutput redirects rendered frames to w. A nil w restores os.Stderr.
+func (pb *ProgressBar) SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stderr
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.out = w
+	pb.vtOnce = sync.Once{}
+	pb.detectTTY()
+}
+
+// SetFinalWriter makes Finish write a single plain summary line to w
+// after the last frame is drawn, e.g. to keep the animation on stderr and
+// a durable record on stdout. A nil w turns the summary off.
+func (pb *ProgressBar) SetFinalWriter(w io.Writer) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.final = w
+}
+
+// notify rings the terminal bell and/or sends an OSC 9 desktop
+// notification naming the bar, as asked for by WithBellOnFinish and
+// WithFinishNotification. Nothing is sent off a terminal or when quiet.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) notify() {
+	if pb.quiet || !pb.tty {
+		return
+	}
+	if pb.notice {
+		name := pb.headerLine
+		if name == "" {
+			name = "polybar"
+		}
+		pb.write([]byte("\033]9;" + name + ": done\a"))
+	}
+	if pb.bell {
+		pb.write([]byte("\a"))
+	}
+}
+
+// summarize writes the Finish summary line to the final writer, if any:
+// the header (if set), the plain status and the total elapsed time.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) summarize() {
+	if pb.final == nil {
+		return
+	}
+	line := pb.status(false) + " in " + pb.elapsed().Round(time.Millisecond).String()
+	if pb.headerLine != "" {
+		line = pb.headerLine + ": " + line
+	}
+	fmt.Fprintln(pb.final, line)
+}
+
+// detectTTY records whether pb.out is an interactive terminal, honouring
+// any WithForceTTY override. Only an *os.File can be a terminal.
+func (pb *ProgressBar) detectTTY() {
+	if pb.forceTTY != nil {
+		pb.tty = *pb.forceTTY
+		return
+	}
+	f, ok := pb.out.(*os.File)
+	pb.tty = ok && term.IsTerminal(int(f.Fd()))
+}
+
 // Start initializes the progress bar display (0 completed out of total).
-func (pb *ProgressBar) Start(total int) {
+// It returns an error, and draws nothing, if total is not positive.
+func (pb *ProgressBar) Start(total int) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.begin(total)
+}
+
+// Reset prepares a finished (or running) bar for a new task of total steps.
+// Progress and timing are cleared and a fresh initial frame is drawn below
+// the previous one; the sequence, header, width and glyphs are kept.
+// Like Start, it rejects a total that is not positive.
+func (pb *ProgressBar) Reset(total int) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.begin(total)
+}
+
+// begin zeroes the run state and draws the first frame.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) begin(total int) error {
+	if err := pb.restart(total); err != nil {
+		return err
+	}
+	pb.progressed()
+	pb.render()
+	return nil
+}
+
+// restart zeroes the run state for a new run of total steps without
+// drawing anything. Callers must hold pb.mu.
+func (pb *ProgressBar) restart(total int) error {
+	if total <= 0 {
+		return fmt.Errorf("polybar: total must be positive, got %d", total)
+	}
+	pb.stopAuto()
+	pb.drawn = 0
+	pb.indeterminate = false
+	pb.aborted = false
 	pb.total = total
 	pb.completed = 0
+	pb.fired = false
+	pb.rearm()
+	pb.started = time.Now()
+	pb.pausedAt = time.Time{}
+	pb.samples = nil
+	pb.weightTotal = 0
+	pb.bytesTotal = 0
+	pb.milestone = 0
+	pb.logged = -1
+	return nil
+}
+
+// StartIndeterminate starts a bar whose total is not known up front. Each
+// Update slides a short segment along the zipper and the status line shows
+// only the running count, until Finish is called.
+func (pb *ProgressBar) StartIndeterminate() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.stopAuto()
+	pb.drawn = 0
+	pb.indeterminate = true
+	pb.aborted = false
+	pb.total = 0
+	pb.completed = 0
+	pb.fired = false
+	pb.rearm()
+	pb.started = time.Now()
+	pb.pausedAt = time.Time{}
+	pb.samples = nil
+	pb.weightTotal = 0
+	pb.bytesTotal = 0
+	pb.milestone = 0
+	pb.logged = -1
+	pb.progressed()
 	pb.render()
 }
 
-// Update increments progress by one step and refreshes.
+// Update increments progress by one step and refreshes. It is Add(1).
+// Progress never advances past total.
 func (pb *ProgressBar) Update() {
-	pb.completed++
+	pb.Add(1)
+}
+
+// Add advances progress by n steps at once and refreshes a single time,
+// e.g. when a batch of records completes. A negative n moves progress
+// back. The result is clamped to [0, total] as in SetProgress. It does
+// nothing on a StartWeighted or StartBytes bar, whose progress is given
+// with AddWeight or AddBytes.
+func (pb *ProgressBar) Add(n int) {
+	pb.mu.Lock()
+	if pb.weightTotal > 0 || pb.bytesTotal > 0 {
+		pb.mu.Unlock()
+		return
+	}
+	pb.completed = max(pb.completed+n, 0)
+	if !pb.indeterminate {
+		pb.completed = min(pb.completed, pb.total)
+	}
+	pb.progressed()
 	pb.render()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
 }
 
 // SetProgress jumps to a given “completed” count and refreshes.
+// The count is clamped to [0, total], so the bar never shows a negative
+// percentage or more than 100%. It does nothing on a StartWeighted or
+// StartBytes bar, whose progress is given with AddWeight or AddBytes.
 func (pb *ProgressBar) SetProgress(completed int) {
+	pb.mu.Lock()
+	if pb.weightTotal > 0 || pb.bytesTotal > 0 {
+		pb.mu.Unlock()
+		return
+	}
+	if completed < 0 {
+		completed = 0
+	}
+	if !pb.indeterminate && completed > pb.total {
+		completed = pb.total
+	}
 	pb.completed = completed
+	pb.progressed()
+	pb.render()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
+}
+
+// AddTotal grows (or, with a negative delta, shrinks) the total mid-run
+// without touching progress, and refreshes so the percentage follows.
+// The total never drops below the completed count or below 1. It does
+// nothing before Start or on an indeterminate, weighted or StartBytes bar.
+func (pb *ProgressBar) AddTotal(delta int) {
+	pb.mu.Lock()
+	if pb.indeterminate || pb.total == 0 || pb.weightTotal > 0 || pb.bytesTotal > 0 {
+		pb.mu.Unlock()
+		return
+	}
+	pb.total = max(pb.total+delta, pb.completed, 1)
+	pb.progressed()
 	pb.render()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
 }
 
 // Finish marks the bar fully complete, then prints a newline.
+// An indeterminate bar takes its final count as the total; one that never
+// counted anything has no total to fill, so its last frame is drawn as it
+// stands. Finish is a no-op once the run has been finished or aborted; on
+// a bar that was never started it only closes the Done channel.
 func (pb *ProgressBar) Finish() {
+	pb.mu.Lock()
+	if pb.ended {
+		pb.mu.Unlock()
+		return
+	}
+	if !pb.indeterminate && pb.total == 0 {
+		pb.end()
+		pb.mu.Unlock()
+		return
+	}
+	if pb.indeterminate && pb.completed > 0 {
+		pb.indeterminate = false
+		pb.total = pb.completed
+	}
 	pb.completed = pb.total
-	pb.render()
-	fmt.Fprintln(os.Stderr)
+	pb.weightDone = pb.weightTotal
+	pb.bytesDone = pb.bytesTotal
+	pb.progressed()
+	pb.stopAuto()
+	pb.halt()
+	pb.notify()
+	pb.summarize()
+	pb.end()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
+}
+
+// Abort stops the bar where it is, without jumping to 100% as Finish does.
+// The final frame is drawn at the current progress with a "✗ FAILED"
+// marker and the reason (if any) on the status line, and the cursor is
+// moved below the bar. OnComplete is not called. Abort is a no-op once
+// the run has been finished or aborted.
+func (pb *ProgressBar) Abort(reason string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.ended {
+		return
+	}
+	pb.aborted = true
+	pb.reason = reason
+	pb.progressed()
+	pb.stopAuto()
+	pb.halt()
+	pb.end()
+}
+
+// Done returns a channel that is closed when Finish or Abort is called,
+// so a supervising goroutine can select on the bar alongside other
+// events. Start, StartIndeterminate and Reset begin a new run with a new
+// channel; one already handed out for an earlier run stays closed.
+func (pb *ProgressBar) Done() <-chan struct{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if pb.done == nil {
+		pb.done = make(chan struct{})
+		if pb.ended {
+			close(pb.done)
+		}
+	}
+	return pb.done
+}
+
+// end marks the run as over, closing the Done channel if one was handed
+// out. Only the first call per run has any effect. Callers must hold
+// pb.mu.
+func (pb *ProgressBar) end() {
+	if pb.ended {
+		return
+	}
+	pb.ended = true
+	if pb.done != nil {
+		close(pb.done)
+	}
+}
+
+// rearm starts a new run for Done: the run is no longer over, and a
+// channel closed by the last one is dropped. Callers must hold pb.mu.
+func (pb *ProgressBar) rearm() {
+	if pb.ended {
+		pb.done = nil
+	}
+	pb.ended = false
 }
 
-// render draws five lines to stderr (overwriting previous five if not first frame).
-// 1) If headerLine != "", print headerLine (alone).
+// OnComplete registers fn to be called once, the first time progress
+// reaches total through Update, SetProgress or Finish. It runs on the
+// goroutine that completed the bar, after the final frame is drawn.
+func (pb *ProgressBar) OnComplete(fn func()) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.onComplete = fn
+}
+
+// OnRender registers fn to be called with the plain-text frame (as
+// returned by Frame) every time the bar refreshes, including the final
+// frame of Finish or Abort, whether or not anything is drawn. Use it to
+// forward the bar to a web dashboard or TUI. fn runs with the bar locked,
+// so it must not call the bar's methods. A nil fn removes the callback.
+func (pb *ProgressBar) OnRender(fn func(frame string)) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.onRender = fn
+}
+
+// rendered passes the current frame to the OnRender callback and the
+// WithFrameHistory ring, if either is in use. Callers must hold pb.mu.
+func (pb *ProgressBar) rendered() {
+	if pb.onRender == nil && pb.historySize == 0 {
+		return
+	}
+	lines := pb.frameLines(false)
+	if lines == nil {
+		return
+	}
+	frame := strings.Join(lines, "\n")
+	if pb.historySize > 0 {
+		if len(pb.history) < pb.historySize {
+			pb.history = append(pb.history, frame)
+		} else {
+			pb.history[pb.historyNext] = frame
+		}
+		pb.historyNext = (pb.historyNext + 1) % pb.historySize
+	}
+	if pb.onRender != nil {
+		pb.onRender(frame)
+	}
+}
+
+// History returns the frames kept by WithFrameHistory as plain text,
+// oldest first. It is nil if the option is not set.
+func (pb *ProgressBar) History() []string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if len(pb.history) < pb.historySize {
+		return append([]string(nil), pb.history...)
+	}
+	return append(append([]string(nil), pb.history[pb.historyNext:]...), pb.history[:pb.historyNext]...)
+}
+
+// completion returns the OnComplete callback if progress has just reached
+// total for the first time this run, or a no-op otherwise. The caller runs
+// it after releasing pb.mu so the callback may use the bar.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) completion() func() {
+	if pb.fired || pb.total == 0 || pb.completed < pb.total || pb.onComplete == nil {
+		return func() {}
+	}
+	pb.fired = true
+	return pb.onComplete
+}
+
+// Clear erases the bar from the terminal, leaving the cursor where its
+// first line was, e.g. to replace it with an error message. It stops
+// auto-refresh; a later Update draws a fresh frame in the same place.
+// Unlike Finish, nothing is left on screen. It does nothing if the bar is
+// not currently drawn in place (after Finish or Abort, or off a terminal).
+func (pb *ProgressBar) Clear() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.stopAuto()
+	if !pb.tty {
+		return
+	}
+	erase := strings.Repeat("\033[F\033[2K", pb.drawn)
+	switch {
+	case pb.crMode():
+		erase = pb.crErase()
+	case pb.anchor && pb.drawn > 0:
+		erase = "\033[u\033[J"
+	}
+	if pb.write([]byte(erase)) {
+		pb.drawn = 0
+	}
+}
+
+// halt draws the current frame one last time and moves the cursor below it.
+// Before Start there is no frame, so nothing is written at all.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) halt() {
+	pb.rendered()
+	if pb.quiet || pb.grouped || (!pb.indeterminate && pb.total == 0) {
+		return
+	}
+	pb.draw()
+	pb.write([]byte("\n"))
+	pb.drawn = 0
+}
+
+// progressed notes a change in progress for the throughput average, the
+// newest base highlight, the event stream and the milestone log. Callers must hold pb.mu.
+func (pb *ProgressBar) progressed() {
+	pb.record()
+	pb.trackFill()
+	pb.emit()
+	pb.milestones()
+}
+
+// trackFill notes whether the last progress change filled in more bases,
+// for WithHighlightNewBase. Callers must hold pb.mu.
+func (pb *ProgressBar) trackFill() {
+	if !pb.highlightNew {
+		return
+	}
+	hi := 0
+	if !pb.indeterminate && pb.total > 0 {
+		_, hi = pb.window()
+	}
+	pb.grew = hi > pb.lastHi
+	pb.lastHi = hi
+}
+
+// Percent returns progress as a percentage of total, or 0 before Start.
+func (pb *ProgressBar) Percent() float64 {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.percent()
+}
+
+// Completed returns the number of steps completed so far.
+func (pb *ProgressBar) Completed() int {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.completed
+}
+
+// Total returns the number of steps passed to Start, or 0 before Start.
+func (pb *ProgressBar) Total() int {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.total
+}
+
+// percent returns completed as a percentage of total, never above 100:
+// progress past total is clamped rather than growing the total (that is
+// what AddTotal is for), so Finish never shows the bar going backwards.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) percent() float64 {
+	if pb.total == 0 {
+		return 0
+	}
+	return min(float64(pb.completed)/float64(pb.total)*100, 100)
+}
+
+// Frame returns the current frame as plain text: the header (if any),
+// zipper, top strand, complement, primer and percentage lines joined by
+// newlines, with no ANSI escapes. It returns "" before Start.
+func (pb *ProgressBar) Frame() string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return strings.Join(pb.frameLines(false), "\n")
+}
+
+// CaptureFrames renders the frame the bar would show at each of steps out
+// of total, as Frame does, without drawing anything or disturbing the
+// current run. Steps are clamped to [0, total] like SetProgress. It is
+// meant for snapshot tests of the animation, and returns nil if total is
+// not positive.
+func (pb *ProgressBar) CaptureFrames(total int, steps []int) []string {
+	if total <= 0 {
+		return nil
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	defer pb.loadRun(pb.saveRun())
+	pb.total = total
+	pb.indeterminate = false
+	pb.aborted = false
+	pb.started = time.Now()
+	pb.pausedAt = time.Time{}
+	pb.samples = nil
+	pb.weightTotal = 0
+	pb.bytesTotal = 0
+
+	frames := make([]string, 0, len(steps))
+	for _, step := range steps {
+		pb.completed = min(max(step, 0), total)
+		frames = append(frames, strings.Join(pb.frameLines(false), "\n"))
+	}
+	return frames
+}
+
+// runState is the part of a ProgressBar that changes as a run progresses.
+type runState struct {
+	total, completed int
+	indeterminate    bool
+	aborted          bool
+	started          time.Time
+	pausedAt         time.Time
+	samples          []rateSample
+	weightTotal      float64
+	weightDone       float64
+	bytesTotal       int64
+	bytesDone        int64
+}
+
+// saveRun returns the current run state. Callers must hold pb.mu.
+func (pb *ProgressBar) saveRun() runState {
+	return runState{
+		total:         pb.total,
+		completed:     pb.completed,
+		indeterminate: pb.indeterminate,
+		aborted:       pb.aborted,
+		started:       pb.started,
+		pausedAt:      pb.pausedAt,
+		samples:       pb.samples,
+		weightTotal:   pb.weightTotal,
+		weightDone:    pb.weightDone,
+		bytesTotal:    pb.bytesTotal,
+		bytesDone:     pb.bytesDone,
+	}
+}
+
+// loadRun puts back a run state taken by saveRun. Callers must hold pb.mu.
+func (pb *ProgressBar) loadRun(s runState) {
+	pb.total = s.total
+	pb.completed = s.completed
+	pb.indeterminate = s.indeterminate
+	pb.aborted = s.aborted
+	pb.started = s.started
+	pb.pausedAt = s.pausedAt
+	pb.samples = s.samples
+	pb.weightTotal = s.weightTotal
+	pb.weightDone = s.weightDone
+	pb.bytesTotal = s.bytesTotal
+	pb.bytesDone = s.bytesDone
+}
+
+// frameLines builds the lines of the current frame, top to bottom. With
+// color set, bases on the strand lines are wrapped in ANSI colors.
+// Callers must hold pb.mu.
+// 1) If headerLine != "", headerLine (alone), then the AddPhase and
+// WithRuler lines if any.
 // 2) Zipper line (“3′” + zipper characters spanning pb.width).
-// 3) Top strand: “--” + first pos bases of template.
-// 4) Complement: “--” + first pos bases of complement.
-// 5) Primer line: “5′” + `┴` repeated pos times + “===>”.
+// 3) Top strand: “--” + the filled bases of template.
+// 4) Complement: “--” + the filled bases of complement (from the right
+// end with WithAntiparallelFill), then the mismatch markers and
+// amino-acid track if enabled.
+// 5) Primer line: “5′” + `┴` under each filled base (not padding) + “===>”.
 // 6) Percentage line “xx.x% (c/t)”.
-func (pb *ProgressBar) render() {
-	if pb.total == 0 {
-		return
+func (pb *ProgressBar) frameLines(color bool) []string {
+	if !pb.indeterminate && pb.total == 0 {
+		return nil
+	}
+	if pb.compact {
+		return []string{pb.compactLine(color)}
+	}
+	if pb.pairedEnd {
+		return pb.pairedLines(color)
+	}
+	if pb.hairpin {
+		return pb.hairpinLines(color)
 	}
 
-	// 1) Calculate how many bases to “fill in” (pos), scaled to width.
-	pos := pb.completed * pb.width / pb.total
-	if pos > pb.width {
-		pos = pb.width
+	// 1) Work out which bases [lo, hi) to “fill in”. Normally that is the
+	//    first pos bases, with pos scaled to width. With WithAutoWidth on a
+	//    narrow terminal only a span of the strands starting at off is
+	//    shown, and lo/hi are made relative to it.
+	//    The primer only runs under real bases [plo, phi), never under
+	//    the padding of a sequence narrower than the bar.
+	lo, hi := pb.window()
+	phi := min(hi, pb.seqHi)
+	plo := min(max(lo, pb.seqLo), phi)
+	off, span := pb.viewport(hi)
+	var lineProtein string
+	if pb.frame > 0 {
+		lineProtein = translationLine(pb.protein, pb.frame, lo, hi)
+		lineProtein = string([]rune(lineProtein)[min(off, utf8.RuneCountInString(lineProtein)):])
+	}
+	// With WithAntiparallelFill the complement shows the mirror image of
+	// [lo, hi), counted from the right end, clipped to the viewport.
+	clo, chi := lo, hi
+	if pb.antiparallel {
+		clo, chi = pb.width-hi, pb.width-lo
 	}
+	clo, chi = min(max(clo-off, 0), span), min(max(chi-off, 0), span)
+	if clo >= chi {
+		clo, chi = 0, 0
+	}
+	lo, hi = max(lo-off, 0), max(hi-off, 0)
+	plo, phi = max(plo-off, 0), max(phi-off, 0)
+	top, comp := pb.topStrand[off:off+span], pb.complement[off:off+span]
+	gap, cgap := strings.Repeat(" ", lo), strings.Repeat(" ", clo)
+
+	pal := pb.palette(color)
 
 	// 2) Build zipper line with “3′” label.
-	lineZipper := "3'" + strings.Repeat(zipperChar, pb.width)
+	zipLabel, _ := pb.labels()
+	lineZipper := zipLabel + strings.Repeat(pb.zipper, span)
 
-	// 3) Build top-strand (template) showing only the first pos bases, with “--” in front.
-	var lineTop string
-	if pos <= len(pb.topStrand) {
-		lineTop = "--" + pb.topStrand[:pos]
-	} else {
-		lineTop = "--" + pb.topStrand
+	// 3) Build top-strand (template) showing only the filled bases, with “--” in front.
+	lineTop := "--" + gap + paint(top[lo:hi], off+lo, pal)
+
+	// 4) Build complement line similarly. A reverse complement reads 5′→3′
+	//    left to right, so it is marked “5′” instead of “--”.
+	compPrefix := "--"
+	if pb.revComp {
+		compPrefix = "5'"
 	}
+	lineComplement := compPrefix + cgap + paint(comp[clo:chi], off+clo, pal)
 
-	// 4) Build complement line similarly.
-	var lineComplement string
-	if pos <= len(pb.complement) {
-		lineComplement = "--" + pb.complement[:pos]
-	} else {
-		lineComplement = "--" + pb.complement
+	// 5) Build primer line (“5′” + baseChar under each filled base + arrow).
+	linePrimer := pb.primerLine(plo, phi)
+
+	// 6) Percentage line; with no known total, just the running count.
+	linePercent := "(" + pb.count(pb.completed) + pb.unitSuffix() + ")"
+	if !pb.indeterminate {
+		linePercent = pb.status(color)
 	}
 
-	// 5) Build primer line (“5′” + baseChar × pos + arrow).
-	var linePrimer string
-	if pos < pb.width {
-		linePrimer = "5'" + strings.Repeat(baseChar, pos) + arrowText
-	} else {
-		linePrimer = "5'" + strings.Repeat(baseChar, pb.width) + arrowText
+	// Mismatch markers under the bases shown so far.
+	var lineMarks string
+	if pb.duplex {
+		lineMarks = strings.TrimRight(cgap+string(pb.mismatches[off+clo:off+chi]), " ")
 	}
 
-	// 6) Percentage line
-	percent := float64(pb.completed) / float64(pb.total) * 100
-	linePercent := fmt.Sprintf("%.1f%% (%d/%d)", percent, pb.completed, pb.total)
+	return pb.stack(pb.phaseLine(off, span), pb.rulerLine(off, span), lineZipper, lineTop, pb.tickLine(off, span), lineComplement, lineMarks, lineProtein, linePrimer, linePercent)
+}
 
-	// 7) If not the very first frame (completed > 0), move cursor up 5 lines to overwrite.
-	if pb.completed > 0 {
-		for i := 0; i < 5+(func() int {
-			if pb.headerLine != "" {
-				return 1
+// window returns the range [lo, hi) of bases that are filled in:
+//   - normally the first fill() bases;
+//   - in indeterminate mode, the sliding segment;
+//   - in replication-fork mode, fill() bases centred on the middle of
+//     the strands, so the fill grows outward in both directions.
+//
+// Callers must hold pb.mu.
+func (pb *ProgressBar) window() (lo, hi int) {
+	switch {
+	case pb.indeterminate:
+		return pb.segment()
+	case pb.fork:
+		pos := pb.fill()
+		lo = (pb.width - pos) / 2
+		return lo, lo + pos
+	default:
+		return 0, pb.fill()
+	}
+}
+
+// fill returns how many bases are shown filled: position(), or with
+// WithCountdown the rest of the width, so the bar empties as progress is
+// made. Callers must hold pb.mu.
+func (pb *ProgressBar) fill() int {
+	if pb.countdown {
+		return pb.width - pb.position()
+	}
+	return pb.position()
+}
+
+// primerLine builds the primer under the filled bases [lo, hi). In
+// replication-fork mode a mirrored arrow leads the primer out to the left,
+// clipped where it would run past the label. Callers must hold pb.mu.
+func (pb *ProgressBar) primerLine(lo, hi int) string {
+	lead := strings.Repeat(" ", lo)
+	if pb.fork {
+		left := []rune(mirrorArrow(pb.arrowhead()))
+		if len(left) > lo {
+			left = left[len(left)-lo:]
+		}
+		lead = strings.Repeat(" ", lo-len(left)) + string(left)
+	}
+	_, primerLabel := pb.labels()
+	return primerLabel + lead + strings.Repeat(pb.base, hi-lo) + pb.arrowhead()
+}
+
+// labels returns the end labels for the zipper and primer lines: the
+// WithStrandLabels pair (3' and 5' by default), swapped when
+// WithStrandOrder puts the complement first so orientation stays right.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) labels() (zipper, primer string) {
+	if pb.complementFirst {
+		return pb.bottom, pb.top
+	}
+	return pb.top, pb.bottom
+}
+
+// arrowhead returns the arrow to draw at the end of the primer. Once a
+// determinate run is complete that is the WithFinishArrow text or, if
+// none was given and the default arrow is in use, finishText, showing
+// that the fork has reached the end. Callers must hold pb.mu.
+func (pb *ProgressBar) arrowhead() string {
+	if pb.indeterminate || pb.total == 0 || pb.completed < pb.total {
+		return pb.arrow
+	}
+	switch {
+	case pb.finishArrow != nil:
+		return *pb.finishArrow
+	case pb.arrow == arrowText:
+		return finishText
+	default:
+		return pb.arrow
+	}
+}
+
+// mirrorArrow reverses arrow and flips its direction, turning "===>" into
+// "<===".
+func mirrorArrow(arrow string) string {
+	r := []rune(arrow)
+	reverseRunes(r)
+	for i, c := range r {
+		switch c {
+		case '>':
+			r[i] = '<'
+		case '<':
+			r[i] = '>'
+		}
+	}
+	return string(r)
+}
+
+// counts formats progress against the total as the status line shows it:
+// the weights on a StartWeighted run, humanized sizes on a StartBytes run
+// and the step counts otherwise, e.g. "24/120". Callers must hold pb.mu.
+func (pb *ProgressBar) counts() string {
+	switch {
+	case pb.bytesTotal > 0:
+		return humanBytes(pb.bytesDone) + "/" + humanBytes(pb.bytesTotal)
+	case pb.weightTotal > 0:
+		return weightText(pb.weightDone) + "/" + weightText(pb.weightTotal) + pb.unitSuffix()
+	}
+	return pb.count(pb.completed) + "/" + pb.count(pb.total) + pb.unitSuffix()
+}
+
+// status builds the percentage line, “xx.x% (c/t)” (or the WithStatusFunc
+// text) followed by any enabled extras and, after Abort, the failure
+// marker (red when color is set). Callers must hold pb.mu.
+func (pb *ProgressBar) status(color bool) string {
+	var line string
+	switch {
+	case pb.statusFunc != nil:
+		line = pb.statusFunc(pb.completed, pb.total, pb.percent(), pb.elapsed())
+	default:
+		line = fmt.Sprintf("%.*f%% (%s)", pb.precision, pb.percent(), pb.counts())
+	}
+	if pb.showETA {
+		line += " " + pb.timing()
+	}
+	if pb.showRate {
+		line += " " + pb.rateText()
+	}
+	if pb.showGC {
+		line += fmt.Sprintf(" GC=%.1f%%", pb.gc)
+	}
+	if pb.showTm {
+		if math.IsNaN(pb.tm) {
+			line += " Tm=n/a"
+		} else {
+			unit := "°C"
+			if pb.ascii {
+				unit = "C"
 			}
-			return 0
-		}()); i++ {
-			// If headerLine exists, that's one extra line to overwrite.
-			fmt.Fprint(os.Stderr, "\033[F")
+			line += fmt.Sprintf(" Tm=%.0f%s", pb.tm, unit)
 		}
 	}
+	if pb.showCoords {
+		line += " " + pb.coords()
+	}
+	if pb.showRemaining {
+		line += " " + pb.remaining()
+	}
+	if name := pb.currentPhase(); name != "" {
+		line += " [" + name + "]"
+	}
+	if pb.aborted {
+		marker := "✗ FAILED"
+		if pb.ascii {
+			marker = "x FAILED"
+		}
+		if pb.reason != "" {
+			marker += ": " + pb.reason
+		}
+		if color {
+			marker = ansiRed + marker + ansiReset
+		}
+		line += " " + marker
+	}
+	return line
+}
+
+// coords formats how far the fill has reached into the real bases of the
+// sequence, padding aside, e.g. "120/480 bp". Callers must hold pb.mu.
+func (pb *ProgressBar) coords() string {
+	_, hi := pb.window()
+	pos := min(max(hi, pb.seqLo), pb.seqHi) - pb.seqLo
+	return fmt.Sprintf("%d/%d bp", pos, pb.seqHi-pb.seqLo)
+}
+
+// remaining formats how many bases are left to go, e.g. "360 bp
+// remaining". When WithUnit("bp") says the steps are bases, that is total
+// minus completed; otherwise the work left is scaled to the sequence's
+// length on the bar. Callers must hold pb.mu.
+func (pb *ProgressBar) remaining() string {
+	left := pb.total - pb.completed
+	if pb.unit != "bp" || pb.weightTotal > 0 || pb.bytesTotal > 0 {
+		left = int(math.Round((100 - pb.percent()) / 100 * float64(pb.seqHi-pb.seqLo)))
+	}
+	return fmt.Sprintf("%d bp remaining", max(left, 0))
+}
+
+// compactLine builds the single-line form of the frame used by
+// WithCompact: the header (if any), a bar pb.width wide (or narrower under
+// WithMaxWidth or WithAutoWidth) filled with the base glyph, and the
+// status. Callers must hold pb.mu.
+func (pb *ProgressBar) compactLine(color bool) string {
+	status := "(" + pb.count(pb.completed) + pb.unitSuffix() + ")"
+	if !pb.indeterminate {
+		status = pb.status(color)
+	}
+	if pb.noPercent {
+		status = ""
+	}
+	prefix := ""
+	if pb.headerLine != "" {
+		prefix = pb.headerLine + " "
+	}
+
+	// The bar shrinks, scaled, to at most WithMaxWidth and, with
+	// WithAutoWidth, to leave room for the header and status on a narrow
+	// terminal.
+	lo, hi := pb.window()
+	size := pb.width
+	if pb.maxWidth > 0 {
+		size = min(size, pb.maxWidth)
+	}
+	if cols := pb.columns(); cols > 0 {
+		size = min(size, cols-utf8.RuneCountInString(prefix+status)-3)
+	}
+	if size < pb.width {
+		size = max(size, 1)
+		lo, hi = lo*size/pb.width, hi*size/pb.width
+	}
+	bar := strings.Repeat(" ", lo) + strings.Repeat(pb.base, hi-lo) + strings.Repeat(" ", size-hi)
+	if pb.noPercent {
+		return prefix + "[" + bar + "]"
+	}
+	return prefix + "[" + bar + "] " + status
+}
+
+// segment returns the [start, end) window that slides along the strands
+// in indeterminate mode: a quarter of the width wide, moving one base per
+// Update and wrapping at the end. Callers must hold pb.mu.
+func (pb *ProgressBar) segment() (start, end int) {
+	seg := pb.width / 4
+	if seg < 1 {
+		seg = 1
+	}
+	start = pb.completed % pb.width
+	end = start + seg
+	if end > pb.width {
+		end = pb.width
+	}
+	return start, end
+}
 
-	// 8) Actually print:
+// stack assembles a frame from its parts, adding the header, phase ruler
+// and coordinate ruler above, pairing ticks between the strands, and the
+// mismatch markers and amino-acid track (indented past the “--” prefix)
+// below the complement when they are enabled, and dropping the status
+// under WithoutPercent. Callers must hold pb.mu.
+func (pb *ProgressBar) stack(phases, ruler, zipper, top, ticks, complement, marks, protein, primer, status string) []string {
+	lines := make([]string, 0, 10)
 	if pb.headerLine != "" {
-		fmt.Fprintln(os.Stderr, pb.headerLine)
+		lines = append(lines, clip(pb.headerLine, pb.columns()))
+	}
+	if len(pb.phases) > 0 {
+		lines = append(lines, phases)
+	}
+	if pb.ruler > 0 {
+		lines = append(lines, ruler)
+	}
+	if pb.complementFirst {
+		top, complement = complement, top
+	}
+	lines = append(lines, zipper, top)
+	if ticks != "" {
+		lines = append(lines, ticks)
+	}
+	lines = append(lines, complement)
+	if pb.duplex {
+		lines = append(lines, "  "+marks)
+	}
+	if pb.frame > 0 {
+		lines = append(lines, "  "+protein)
+	}
+	lines = append(lines, primer)
+	if !pb.noPercent {
+		lines = append(lines, clip(status, pb.columns()))
+	}
+	return lines
+}
+
+// tickLine returns the WithPairingTicks line for the bases [off,
+// off+span), '|' between each complementary pair, once the run is
+// complete; otherwise "". Callers must hold pb.mu.
+func (pb *ProgressBar) tickLine(off, span int) string {
+	if !pb.showTicks || pb.revComp || pb.indeterminate || pb.total == 0 || pb.completed < pb.total {
+		return ""
+	}
+	return strings.TrimRight("  "+string(pb.ticks[off:off+span]), " ")
+}
+
+// timing formats the elapsed time since Start and a linear estimate of the
+// time remaining, e.g. "elapsed=12s eta=30s". The ETA is "--" until at
+// least one step has completed.
+func (pb *ProgressBar) timing() string {
+	elapsed := pb.elapsed()
+	eta := "--"
+	if pb.completed > 0 {
+		remaining := pb.total - pb.completed
+		if remaining < 0 {
+			remaining = 0
+		}
+		perStep := elapsed / time.Duration(pb.completed)
+		eta = (perStep * time.Duration(remaining)).Round(time.Second).String()
+	}
+	return fmt.Sprintf("elapsed=%s eta=%s", elapsed.Round(time.Second), eta)
+}
+
+// position returns how many bases to “fill in”, in [0, pb.width].
+// Normally completed is scaled to width; in scroll mode with more steps
+// than bases, each step fills one base and the fill wraps back to the
+// start every pb.width steps. A WithEasing curve reshapes the scaled
+// fill, but not the scroll. Callers must hold pb.mu.
+func (pb *ProgressBar) position() int {
+	if pb.completed >= pb.total {
+		return pb.width
+	}
+	if pb.scroll && pb.total > pb.width && pb.completed > 0 {
+		return (pb.completed-1)%pb.width + 1
+	}
+	if pb.easing != nil {
+		p := pb.easing(float64(pb.completed) / float64(pb.total))
+		return int(min(max(p, 0), 1) * float64(pb.width))
+	}
+	pos := pb.completed * pb.width / pb.total
+	if pos > pb.width {
+		pos = pb.width
+	}
+	return pos
+}
+
+// Flush draws the current frame right away, ignoring WithMinInterval, e.g.
+// just before printing a message so the screen is up to date. Like any
+// intermediate frame it is only drawn on a terminal.
+func (pb *ProgressBar) Flush() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.rendered()
+	if pb.quiet || pb.grouped || !pb.tty {
+		return
+	}
+	pb.draw()
+}
+
+// render refreshes the animation after a progress change. When pb.out is
+// not a terminal the intermediate frames are skipped, so logs and pipes
+// only receive a "Progress: 37%" line per whole percent (see
+// logPercent) and the final frame written by Finish. With WithMinInterval,
+// frames that come too soon after the last one are skipped too. Nothing
+// is drawn while paused, and bars in a Group never draw themselves.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) render() {
+	pb.rendered()
+	if pb.quiet || pb.grouped || pb.paused() {
+		return
+	}
+	if !pb.tty {
+		pb.logPercent()
+		return
+	}
+	if pb.drawn > 0 && time.Since(pb.lastDraw) < pb.minInterval {
+		return
+	}
+	pb.draw()
+}
+
+// logPercent writes a plain "Progress: 37%" line (named after the header,
+// if any) each time the whole percentage goes up, so CI logs show steady
+// progress without cursor codes. Each percentage is written once per run.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) logPercent() {
+	if pb.indeterminate || pb.total == 0 {
+		return
+	}
+	pct := int(pb.percent())
+	if pct <= pb.logged {
+		return
+	}
+	pb.logged = pct
+	name := pb.headerLine
+	if name == "" {
+		name = "Progress"
+	}
+	pb.write([]byte(fmt.Sprintf("%s%s: %d%%\n", pb.stamp(), name, pct)))
+}
+
+// stamp returns the current time in the WithTimestampPrefix layout and a
+// space, or "" when no layout is set. Callers must hold pb.mu.
+func (pb *ProgressBar) stamp() string {
+	if pb.timestamp == "" {
+		return ""
+	}
+	return time.Now().Format(pb.timestamp) + " "
+}
+
+// draw writes the current frame to pb.out. On a terminal it moves the
+// cursor back up over the previous frame and overwrites it in place,
+// rewriting only the lines that changed. Callers must hold pb.mu.
+func (pb *ProgressBar) draw() {
+	lines := pb.frameLines(pb.colorEnabled())
+	if lines == nil {
+		return
+	}
+	b := &pb.buf
+	b.Reset()
+	if !pb.tty {
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+		pb.write(b.Bytes())
+		return
+	}
+	pb.vtOnce.Do(func() { enableVirtualTerminal(pb.out) })
+	if pb.crMode() {
+		pb.drawLine(lines[0])
+		return
+	}
+
+	// If a frame of the same shape is already on screen, go back up only as
+	// far as its first changed line and step over any later line that is
+	// unchanged; otherwise go up over all of it and redraw every line.
+	// With WithAnchor the first frame saves the cursor position and later
+	// ones restore it, then work down from the first line.
+	same := pb.drawn == len(lines)
+	first := 0
+	switch {
+	case pb.anchor && pb.drawn == 0:
+		b.WriteString("\033[s")
+	case pb.anchor:
+		b.WriteString("\033[u")
+	default:
+		for same && first < len(lines) && lines[first] == pb.last[first] {
+			first++
+		}
+		for i := first; i < pb.drawn; i++ {
+			b.WriteString("\033[F")
+		}
+	}
+	for i := first; i < len(lines); i++ {
+		if same && lines[i] == pb.last[i] {
+			b.WriteString("\033[E")
+			continue
+		}
+		// Clear whatever is left of a longer line from the previous frame.
+		b.WriteString(lines[i])
+		b.WriteString("\033[K\n")
+	}
+	if pb.drawn > len(lines) {
+		// The previous frame was taller (say, the header was just
+		// removed); clear its leftover lines below this one.
+		b.WriteString("\033[J")
+	}
+	if !pb.write(b.Bytes()) {
+		return
+	}
+	pb.drawn = len(lines)
+	pb.last = lines
+	pb.lastDraw = time.Now()
+}
+
+// drawLine overwrites the single line of a WithCarriageReturn frame: a
+// carriage return, the line, and spaces over whatever is left of a longer
+// previous one. The cursor stays on the line, so Finish's newline ends
+// it. Callers must hold pb.mu.
+func (pb *ProgressBar) drawLine(line string) {
+	b := &pb.buf
+	b.Reset()
+	b.WriteByte('\r')
+	b.WriteString(line)
+	if pb.drawn > 0 {
+		if pad := plainWidth(pb.last[0]) - plainWidth(line); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	if !pb.write(b.Bytes()) {
+		return
+	}
+	pb.drawn = 1
+	pb.last = []string{line}
+	pb.lastDraw = time.Now()
+}
+
+// crMode reports whether frames are overwritten with a carriage return
+// rather than cursor-movement escapes (WithCarriageReturn on a compact
+// bar). Callers must hold pb.mu.
+func (pb *ProgressBar) crMode() bool {
+	return pb.carriageReturn && pb.compact
+}
+
+// crErase blanks the line drawn by drawLine, leaving the cursor at its
+// start. Callers must hold pb.mu.
+func (pb *ProgressBar) crErase() string {
+	if pb.drawn == 0 {
+		return ""
+	}
+	return "\r" + strings.Repeat(" ", plainWidth(pb.last[0])) + "\r"
+}
+
+// settle waits for a frame write that WithWriteTimeout left running in
+// the background, so that pb.out can be written to directly.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) settle() {
+	if pb.pending != nil {
+		<-pb.pending
+		pb.pending = nil
+	}
+}
+
+// write sends p to pb.out in a single Write and reports whether it was
+// sent. With WithWriteTimeout, a write that takes too long is left to
+// finish in the background, and anything written before it does is
+// dropped rather than waiting. Callers must hold pb.mu.
+func (pb *ProgressBar) write(p []byte) bool {
+	if pb.writeTimeout <= 0 {
+		pb.out.Write(p)
+		return true
+	}
+	if pb.pending != nil {
+		select {
+		case <-pb.pending:
+			pb.pending = nil
+		default:
+			return false
+		}
+	}
+	// p may be pb.buf, which the next frame reuses while this write could
+	// still be in progress, so the goroutine gets its own copy.
+	p = bytes.Clone(p)
+	done := make(chan struct{})
+	go func(out io.Writer) {
+		out.Write(p)
+		close(done)
+	}(pb.out)
+	timer := time.NewTimer(pb.writeTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		pb.pending = done
 	}
-	fmt.Fprintln(os.Stderr, lineZipper)
-	fmt.Fprintln(os.Stderr, lineTop)
-	fmt.Fprintln(os.Stderr, lineComplement)
-	fmt.Fprintln(os.Stderr, linePrimer)
-	fmt.Fprintln(os.Stderr, linePercent)
+	return true
 }
@@ -0,0 +1,19 @@
+package polybar
+
+import (
+	"io"
+	"testing"
+)
+
+// BenchmarkUpdate measures the render path alone: terminal mode is forced
+// so every Update builds and writes a frame, and the output is discarded.
+func BenchmarkUpdate(b *testing.B) {
+	pb := New("ATCGATCGATCGATCG", "bench", WithForceTTY(true))
+	pb.SetOutput(io.Discard)
+	pb.Start(b.N + 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pb.Update()
+	}
+}
@@ -0,0 +1,433 @@
+package polybar
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestMultiByteSequence(t *testing.T) {
+	tests := []struct {
+		name  string
+		seq   string
+		opts  []Option
+		width int
+	}{
+		{"em dash", "AC—GT", nil, 5},
+		{"accented letter", "ÅCGT", nil, 4},
+		{"combining mark", "ACG\u0301T", nil, 5},
+		{"padded", "ÅC", []Option{WithWidth(6)}, 6},
+		{"multi-byte padding", "ACGT", []Option{WithWidth(8), WithPadChar('·')}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := New(tt.seq, "", append([]Option{WithForceTTY(false)}, tt.opts...)...)
+			pb.SetOutput(io.Discard)
+			pb.Start(3)
+			for step := 0; step <= 3; step++ {
+				pb.SetProgress(step)
+				if frame := pb.Frame(); !utf8.ValidString(frame) {
+					t.Fatalf("frame is not valid UTF-8: %q", frame)
+				}
+			}
+			lines := strings.Split(pb.Frame(), "\n")
+			for _, line := range lines[1:3] { // the two strands
+				if got := utf8.RuneCountInString(line); got != 2+tt.width {
+					t.Errorf("strand line %q is %d runes wide, want %d", line, got, 2+tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestStartRejectsTotalsBelowOne(t *testing.T) {
+	for _, total := range []int{0, -1, -100} {
+		var out bytes.Buffer
+		pb := New("ACGT", "", WithForceTTY(true))
+		pb.SetOutput(&out)
+		if err := pb.Start(total); err == nil {
+			t.Errorf("Start(%d) returned no error", total)
+		}
+		if err := pb.Reset(total); err == nil {
+			t.Errorf("Reset(%d) returned no error", total)
+		}
+		pb.Update()
+		pb.SetProgress(2)
+		if out.Len() != 0 {
+			t.Errorf("after Start(%d) the bar wrote %q, want nothing", total, out.String())
+		}
+		if frame := pb.Frame(); frame != "" {
+			t.Errorf("after Start(%d) Frame() = %q, want \"\"", total, frame)
+		}
+	}
+}
+
+func TestReverseComplement(t *testing.T) {
+	tests := []struct {
+		seq, want string
+	}{
+		{"ATGC", "GCAT"},
+		{"GAATTCAAG", "CTTGAATTC"},
+		{"AAAACCC", "GGGTTTT"},
+		{"ACGTN-", "-NACGT"},
+	}
+	for _, tt := range tests {
+		pb := New(tt.seq, "", WithForceTTY(false), WithReverseComplement())
+		pb.SetOutput(io.Discard)
+		pb.Start(1)
+		pb.SetProgress(1)
+		lines := strings.Split(pb.Frame(), "\n")
+		if got := lines[2]; got != "5'"+tt.want {
+			t.Errorf("reverse complement of %s = %q, want %q", tt.seq, got, "5'"+tt.want)
+		}
+	}
+}
+
+func TestPreserveCase(t *testing.T) {
+	tests := []struct {
+		name      string
+		seq       string
+		opts      []Option
+		top, comp string
+	}{
+		{"preserved", "atcg", []Option{WithPreserveCase()}, "atcg", "tagc"},
+		{"softmasked", "ACgtAC", []Option{WithPreserveCase()}, "ACgtAC", "TGcaTG"},
+		{"uppercased by default", "atcg", nil, "ATCG", "TAGC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := New(tt.seq, "", append([]Option{WithQuiet(true)}, tt.opts...)...)
+			pb.Start(1)
+			pb.SetProgress(1)
+			lines := strings.Split(pb.Frame(), "\n")
+			if lines[1] != "--"+tt.top || lines[2] != "--"+tt.comp {
+				t.Errorf("strands = %q / %q, want --%s / --%s", lines[1], lines[2], tt.top, tt.comp)
+			}
+		})
+	}
+}
+
+func TestProgressIsClampedToTotal(t *testing.T) {
+	tests := []struct {
+		name string
+		step func(pb *ProgressBar)
+		want int
+	}{
+		{"SetProgress below zero", func(pb *ProgressBar) { pb.SetProgress(-3) }, 0},
+		{"SetProgress past total", func(pb *ProgressBar) { pb.SetProgress(13) }, 10},
+		{"SetProgress in range", func(pb *ProgressBar) { pb.SetProgress(7) }, 7},
+		{"Add below zero", func(pb *ProgressBar) { pb.Add(-5) }, 0},
+		{"Add past total", func(pb *ProgressBar) { pb.Add(25) }, 10},
+		{"Update past total", func(pb *ProgressBar) {
+			for i := 0; i < 12; i++ {
+				pb.Update()
+			}
+		}, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := New("ACGT", "", WithQuiet(true))
+			pb.Start(10)
+			tt.step(pb)
+			if got := pb.Completed(); got != tt.want {
+				t.Errorf("Completed() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutPercentCursorUps(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		opts   []Option
+		lines  int // lines in the frame
+		ups    int // cursor-ups for an Update, which leaves the zipper (and header) alone
+	}{
+		{"default", "", nil, 5, 4},
+		{"without percent", "", []Option{WithoutPercent()}, 4, 3},
+		{"header", "job", nil, 6, 4},
+		{"header without percent", "job", []Option{WithoutPercent()}, 5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := append([]Option{WithForceTTY(true)}, tt.opts...)
+			pb := New("ACGT", tt.header, opts...)
+			pb.SetOutput(&out)
+			pb.Start(4)
+
+			out.Reset()
+			pb.Update()
+			if got := strings.Count(out.String(), "\033[F"); got != tt.ups {
+				t.Errorf("Update moved up %d lines, want %d", got, tt.ups)
+			}
+
+			out.Reset()
+			pb.Clear()
+			if got := strings.Count(out.String(), "\033[F"); got != tt.lines {
+				t.Errorf("Clear moved up %d lines, want %d", got, tt.lines)
+			}
+		})
+	}
+}
+
+func TestDrawRewritesOnlyChangedLines(t *testing.T) {
+	var out bytes.Buffer
+	status := func(int, int, float64, time.Duration) string { return "copying" }
+	pb := New("ACGTACGT", "job", WithForceTTY(true), WithWidth(8), WithBaseChar('#'), WithStatusFunc(status))
+	pb.SetOutput(&out)
+	pb.Start(8)
+	full := out.String()
+	if got := strings.Count(full, "\033[K\n"); got != 6 {
+		t.Fatalf("first frame wrote %d lines, want 6: %q", got, full)
+	}
+
+	// Only the strands and primer change: go back up to the top strand,
+	// rewrite the three changed lines and step over the status line.
+	out.Reset()
+	pb.Update()
+	want := strings.Repeat("\033[F", 4) + "--A\033[K\n" + "--T\033[K\n" + "5'#===>\033[K\n" + "\033[E"
+	if got := out.String(); got != want {
+		t.Errorf("Update wrote %q, want %q", got, want)
+	}
+	if out.Len() >= len(full) {
+		t.Errorf("Update wrote %d bytes, no fewer than the full frame's %d", out.Len(), len(full))
+	}
+
+	out.Reset()
+	pb.SetProgress(1)
+	if out.Len() != 0 {
+		t.Errorf("an unchanged frame wrote %q, want nothing", out.String())
+	}
+
+	// Removing the header shrinks the frame: every line is rewritten and
+	// the one left over below is cleared.
+	out.Reset()
+	pb.SetHeader("")
+	want = strings.Repeat("\033[F", 6) + "3'" + strings.Repeat(pb.zipper, 8) + "\033[K\n" +
+		"--A\033[K\n" + "--T\033[K\n" + "5'#===>\033[K\n" + "copying\033[K\n" + "\033[J"
+	if got := out.String(); got != want {
+		t.Errorf("SetHeader(\"\") wrote %q, want %q", got, want)
+	}
+}
+
+func TestPrimerStopsAtRealBases(t *testing.T) {
+	t.Setenv("LC_ALL", "C.UTF-8") // keep the ┴ primer glyph whatever the test's locale
+	tests := []struct {
+		name   string
+		opts   []Option
+		step   int
+		primer string
+	}{
+		{"part way", nil, 2, "5'┴┴===>"},
+		{"into the padding", nil, 5, "5'┴┴┴┴===>"},
+		{"complete", nil, 10, "5'┴┴┴┴===|"},
+		{"right-aligned, padding only", []Option{WithAlign(AlignRight)}, 5, "5'     ===>"},
+		{"right-aligned, complete", []Option{WithAlign(AlignRight)}, 10, "5'      ┴┴┴┴===|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithQuiet(true), WithWidth(10)}, tt.opts...)
+			pb := New("ACGT", "", opts...)
+			lines := strings.Split(pb.CaptureFrames(10, []int{tt.step})[0], "\n")
+			if got := lines[3]; got != tt.primer {
+				t.Errorf("primer = %q, want %q", got, tt.primer)
+			}
+		})
+	}
+}
+
+func TestComplementDigits(t *testing.T) {
+	tests := []struct {
+		seq, want string
+	}{
+		{"5ACGT3", "3TGCA5"},
+		{"AC5GT", "TGNCA"},
+		{"AC3GT", "TGNCA"},
+		{"A1C9G", "TNGNC"},
+		{"3ACG", "5TGC"},
+	}
+	for _, tt := range tests {
+		if got := string(generateComplement([]rune(tt.seq), dnaPairs)); got != tt.want {
+			t.Errorf("complement of %s = %s, want %s", tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestFinishBeforeStart(t *testing.T) {
+	tests := []struct {
+		name string
+		tty  bool
+	}{
+		{"terminal", true},
+		{"pipe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			pb := New("ACGT", "header", WithForceTTY(tt.tty))
+			pb.SetOutput(&out)
+			called := false
+			pb.OnComplete(func() { called = true })
+			done := pb.Done()
+			pb.Finish()
+			select {
+			case <-done:
+			default:
+				t.Error("Finish before Start left the Done channel open")
+			}
+			if out.Len() != 0 {
+				t.Errorf("Finish before Start wrote %q, want nothing", out.String())
+			}
+			if called {
+				t.Error("Finish before Start called OnComplete")
+			}
+			if got := pb.Percent(); got != 0 {
+				t.Errorf("Percent() = %v, want 0", got)
+			}
+			if frame := pb.Frame(); frame != "" {
+				t.Errorf("Frame() = %q, want \"\"", frame)
+			}
+		})
+	}
+}
+
+func TestPercentNeverPassesHundred(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress int
+		percent  float64
+		status   string
+	}{
+		{"below zero", -20, 0, "0.0% (0/10)"},
+		{"at total", 10, 100, "100.0% (10/10)"},
+		{"past total", 12, 100, "100.0% (10/10)"},
+		{"far past total", 1000, 100, "100.0% (10/10)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := New("ACGT", "", WithQuiet(true))
+			pb.Start(10)
+			pb.SetProgress(tt.progress)
+			if got := pb.Percent(); got != tt.percent {
+				t.Errorf("Percent() = %v, want %v", got, tt.percent)
+			}
+			if frame := pb.Frame(); !strings.HasSuffix(frame, tt.status) {
+				t.Errorf("status line of %q, want %q", frame, tt.status)
+			}
+			pb.Finish()
+			if got := pb.Percent(); got != 100 {
+				t.Errorf("after Finish Percent() = %v, want 100", got)
+			}
+		})
+	}
+}
+
+func TestAntiparallelFill(t *testing.T) {
+	tests := []struct {
+		name      string
+		seq       string
+		opts      []Option
+		step      int
+		top, comp string
+	}{
+		{"empty", "AACCGGTTAC", nil, 0, "--", "--"},
+		{"part way", "AACCGGTTAC", nil, 3, "--AAC", "--       ATG"},
+		{"most of the way", "AACCGGTTAC", nil, 7, "--AACCGGT", "--   GCCAATG"},
+		{"complete", "AACCGGTTAC", nil, 10, "--AACCGGTTAC", "--TTGGCCAATG"},
+		{"window before the complement", "AACCGGTTACAACCGGTTAC", []Option{WithMaxWidth(8)}, 3, "--AACCGG", "--"},
+		{"window over both", "AACCGGTTACAACCGGTTAC", []Option{WithMaxWidth(8)}, 7, "--TTACAACC", "--AATGTTGG"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithQuiet(true), WithAntiparallelFill()}, tt.opts...)
+			pb := New(tt.seq, "", opts...)
+			lines := strings.Split(pb.CaptureFrames(10, []int{tt.step})[0], "\n")
+			if lines[1] != tt.top || lines[2] != tt.comp {
+				t.Errorf("strands = %q / %q, want %q / %q", lines[1], lines[2], tt.top, tt.comp)
+			}
+		})
+	}
+}
+
+func TestNewStrictRejectsBadOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"width", WithWidth(0)},
+		{"max width", WithMaxWidth(0)},
+		{"ruler", WithRuler(-1)},
+		{"translation", WithTranslation(7)},
+		{"frame history", WithFrameHistory(0)},
+		{"milestones", WithMilestoneWriter(io.Discard, 0)},
+		{"precision", WithPercentPrecision(-1)},
+		{"paired-end gap", WithPairedEnd(-3)},
+		{"alignment", WithAlign(Align(9))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStrict("ACGT", "", tt.opt); err == nil {
+				t.Error("NewStrict returned no error")
+			}
+		})
+	}
+	if _, err := NewStrict("ACGT", "", WithWidth(8), WithTranslation(1), WithPairedEnd(0), WithAlign(AlignRight)); err != nil {
+		t.Errorf("NewStrict with valid options: %v", err)
+	}
+}
+
+func TestFinishIndeterminateWithNothingCounted(t *testing.T) {
+	var out bytes.Buffer
+	pb := New("ACGT", "", WithForceTTY(true))
+	pb.SetOutput(&out)
+	if err := pb.ScanProgress(strings.NewReader(""), 0, func(string) error { return nil }); err != nil {
+		t.Fatalf("ScanProgress on empty input: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "(0)\033[K\n\n") {
+		t.Errorf("output %q does not end with the final frame and a newline", out.String())
+	}
+	if pb.drawn != 0 {
+		t.Errorf("after Finish %d lines are still counted as drawn, want 0", pb.drawn)
+	}
+}
+
+func TestFinishAndAbortAfterTheRunEnds(t *testing.T) {
+	tests := []struct {
+		name        string
+		first, then func(pb *ProgressBar)
+		percent     float64
+	}{
+		{"Finish after Abort", func(pb *ProgressBar) { pb.Abort("disk full") }, (*ProgressBar).Finish, 50},
+		{"Abort after Finish", (*ProgressBar).Finish, func(pb *ProgressBar) { pb.Abort("late") }, 100},
+		{"Finish twice", (*ProgressBar).Finish, (*ProgressBar).Finish, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			pb := New("ACGT", "", WithForceTTY(true))
+			pb.SetOutput(&out)
+			calls := 0
+			pb.OnComplete(func() { calls++ })
+			pb.Start(4)
+			pb.SetProgress(2)
+			tt.first(pb)
+			frame, calledBefore := out.String(), calls
+
+			tt.then(pb)
+			if out.String() != frame {
+				t.Errorf("second call wrote %q, want nothing", strings.TrimPrefix(out.String(), frame))
+			}
+			if calls != calledBefore {
+				t.Errorf("second call ran OnComplete")
+			}
+			if got := pb.Percent(); got != tt.percent {
+				t.Errorf("Percent() = %v, want %v", got, tt.percent)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package polybar
+
+import "io"
+
+// ProxyReader wraps r so that every Read advances the bar by the number of
+// bytes read. Start the bar with the content length (e.g. a file size or an
+// HTTP Content-Length) first, with Start or, for large files, StartBytes;
+// the bar then tracks an io.Copy from the returned reader.
+func (pb *ProgressBar) ProxyReader(r io.Reader) io.Reader {
+	return &proxyReader{pb: pb, r: r}
+}
+
+// ProxyWriter wraps w so that every Write advances the bar by the number of
+// bytes written. Start the bar with the expected byte count first.
+func (pb *ProgressBar) ProxyWriter(w io.Writer) io.Writer {
+	return &proxyWriter{pb: pb, w: w}
+}
+
+// proxyReader counts bytes passing through Read.
+type proxyReader struct {
+	pb *ProgressBar
+	r  io.Reader
+	n  int // cumulative bytes read
+}
+
+func (p *proxyReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.n += n
+		p.pb.proxied(p.n, n)
+	}
+	return n, err
+}
+
+// proxyWriter counts bytes passing through Write.
+type proxyWriter struct {
+	pb *ProgressBar
+	w  io.Writer
+	n  int // cumulative bytes written
+}
+
+func (p *proxyWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.n += n
+		p.pb.proxied(p.n, n)
+	}
+	return n, err
+}
+
+// proxied advances the bar for a proxy that has passed n more bytes, total
+// so far: by n bytes on a StartBytes run, otherwise to total steps.
+func (pb *ProgressBar) proxied(total, n int) {
+	pb.mu.Lock()
+	byBytes := pb.bytesTotal > 0
+	pb.mu.Unlock()
+	if byBytes {
+		pb.AddBytes(int64(n))
+	} else {
+		pb.SetProgress(total)
+	}
+}
@@ -0,0 +1,57 @@
+package polybar
+
+import (
+	"fmt"
+	"time"
+)
+
+// rateWindow is how many recent progress samples the throughput average
+// spans. Small enough to follow real changes, large enough not to jitter.
+const rateWindow = 10
+
+// rateSample is the progress count observed at a moment in time.
+type rateSample struct {
+	at        time.Time
+	completed int
+}
+
+// record notes the current progress for the throughput average, keeping
+// only the last rateWindow samples. Callers must hold pb.mu.
+func (pb *ProgressBar) record() {
+	pb.samples = append(pb.samples, rateSample{at: pb.now(), completed: pb.completed})
+	if len(pb.samples) > rateWindow {
+		pb.samples = pb.samples[len(pb.samples)-rateWindow:]
+	}
+}
+
+// rate returns the items (or, for a weighted run, units of weight, or for
+// a StartBytes run, bytes) per second over the sampled window, or 0 when
+// there isn't enough history yet. Callers must hold pb.mu.
+func (pb *ProgressBar) rate() float64 {
+	if len(pb.samples) < 2 {
+		return 0
+	}
+	first, last := pb.samples[0], pb.samples[len(pb.samples)-1]
+	secs := last.at.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	r := float64(last.completed-first.completed) / secs
+	switch {
+	case pb.weightTotal > 0:
+		// Steps of a weighted run are slices of the total weight.
+		r *= pb.weightTotal / float64(pb.total)
+	case pb.bytesTotal > 0:
+		r *= float64(pb.bytesTotal) / float64(pb.total)
+	}
+	return r
+}
+
+// rateText formats the throughput for the status line, e.g. "12.5/s", or
+// for a StartBytes run "3.2 MB/s". Callers must hold pb.mu.
+func (pb *ProgressBar) rateText() string {
+	if pb.bytesTotal > 0 {
+		return humanBytes(int64(pb.rate())) + "/s"
+	}
+	return fmt.Sprintf("%.1f/s", pb.rate())
+}
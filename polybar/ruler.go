@@ -0,0 +1,27 @@
+package polybar
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rulerLine builds the WithRuler coordinate line for the bases
+// [off, off+span): a '|' under every step-th base, followed by its
+// 1-based position where there is room before the next tick. It is ""
+// when the ruler is off. Callers must hold pb.mu.
+func (pb *ProgressBar) rulerLine(off, span int) string {
+	if pb.ruler == 0 {
+		return ""
+	}
+	ruler := []rune(strings.Repeat(" ", pb.width))
+	for pos := pb.ruler; pos <= pb.width; pos += pb.ruler {
+		mark := "|" + strconv.Itoa(pos)
+		if len(mark) > pb.ruler || pos-1+len(mark) > pb.width {
+			mark = "|"
+		}
+		copy(ruler[pos-1:], []rune(mark))
+	}
+	zipLabel, _ := pb.labels()
+	prefix := strings.Repeat(" ", len([]rune(zipLabel)))
+	return strings.TrimRight(prefix+string(ruler[off:off+span]), " ")
+}
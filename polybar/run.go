@@ -0,0 +1,72 @@
+package polybar
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// RunWithContext starts the bar with total steps and calls step for each
+// i in [0, total), advancing the bar after every successful call. It stops
+// early if ctx is cancelled (returning ctx.Err()) or if step returns an
+// error (returning that error); either way the bar is aborted with the
+// error as the reason, leaving the last frame on screen at the progress
+// reached. When all steps succeed the bar is finished and nil is returned.
+// A total that is not positive is rejected as by Start.
+func (pb *ProgressBar) RunWithContext(ctx context.Context, total int, step func(i int) error) error {
+	if err := pb.Start(total); err != nil {
+		return err
+	}
+	for i := 0; i < total; i++ {
+		if err := ctx.Err(); err != nil {
+			pb.Abort(err.Error())
+			return err
+		}
+		if err := step(i); err != nil {
+			pb.Abort(err.Error())
+			return err
+		}
+		pb.Update()
+	}
+	pb.Finish()
+	return nil
+}
+
+// maxScanLine is the longest line ScanProgress accepts. bufio.Scanner's
+// default of 64 KiB is too short for a sequence that is not wrapped, such
+// as a whole chromosome on one FASTA line; the buffer only grows this far
+// when a line needs it.
+const maxScanLine = 1 << 30
+
+// ScanProgress reads r line by line, calling fn with each line (without
+// its newline) and advancing the bar once per line, e.g. to show progress
+// through stdin while the data flows on. Lines may be up to 1 GiB long.
+// With a total that is not positive the line count is unknown and the bar
+// runs indeterminate. If fn or the scanner fails (say, on a longer line),
+// the bar is aborted with that error as the reason and the error is
+// returned; otherwise the bar is finished at the end of input and nil is
+// returned.
+func (pb *ProgressBar) ScanProgress(r io.Reader, total int, fn func(line string) error) error {
+	if total > 0 {
+		if err := pb.Start(total); err != nil {
+			return err
+		}
+	} else {
+		pb.StartIndeterminate()
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxScanLine)
+	for sc.Scan() {
+		if err := fn(sc.Text()); err != nil {
+			pb.Abort(err.Error())
+			return err
+		}
+		pb.Update()
+	}
+	if err := sc.Err(); err != nil {
+		pb.Abort(err.Error())
+		return err
+	}
+	pb.Finish()
+	return nil
+}
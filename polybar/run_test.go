@@ -0,0 +1,22 @@
+package polybar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanProgressLongLines(t *testing.T) {
+	long := strings.Repeat("ACGT", 64<<10) // 256 KiB, past bufio.Scanner's default limit
+	var got []int
+	pb := New("ACGT", "", WithQuiet(true))
+	err := pb.ScanProgress(strings.NewReader(">chr1\n"+long+"\n"), 0, func(line string) error {
+		got = append(got, len(line))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanProgress: %v", err)
+	}
+	if len(got) != 2 || got[1] != len(long) {
+		t.Errorf("line lengths = %v, want [5 %d]", got, len(long))
+	}
+}
@@ -0,0 +1,240 @@
+package polybar
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// gcContent returns the percentage of G and C (and S, which is always G or
+// C) among the bases of seq. Gaps and N are left out of the denominator;
+// a sequence with no countable bases has 0% GC.
+func gcContent(seq []rune) float64 {
+	var gc, counted int
+	for _, base := range seq {
+		switch unicode.ToUpper(base) {
+		case '-', 'N':
+			continue
+		case 'G', 'C', 'S':
+			gc++
+		}
+		counted++
+	}
+	if counted == 0 {
+		return 0
+	}
+	return float64(gc) / float64(counted) * 100
+}
+
+// wallaceMax is the longest sequence meltingTemp uses the Wallace rule for.
+const wallaceMax = 30
+
+// meltingTemp estimates the melting temperature of seq in °C. Up to
+// wallaceMax bases it uses the Wallace rule, 2×(A+T) + 4×(G+C); longer
+// sequences use the GC approximation 64.9 + 41×(G+C−16.4)/N. W and S count
+// as A/T and G/C; gaps, N and other ambiguity codes are left out. It
+// returns NaN if no base can be counted.
+func meltingTemp(seq []rune) float64 {
+	var at, gc int
+	for _, base := range seq {
+		switch unicode.ToUpper(base) {
+		case 'A', 'T', 'U', 'W':
+			at++
+		case 'G', 'C', 'S':
+			gc++
+		}
+	}
+	n := at + gc
+	if n == 0 {
+		return math.NaN()
+	}
+	if n <= wallaceMax {
+		return float64(2*at + 4*gc)
+	}
+	return 64.9 + 41*(float64(gc)-16.4)/float64(n)
+}
+
+// reverseRunes reverses s in place.
+func reverseRunes(s []rune) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// codonTable is the standard genetic code, mapping each DNA codon to the
+// one-letter amino-acid code. Stop codons translate to '*'.
+var codonTable = map[string]rune{
+	"TTT": 'F', "TTC": 'F', "TTA": 'L', "TTG": 'L',
+	"CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
+	"ATT": 'I', "ATC": 'I', "ATA": 'I', "ATG": 'M',
+	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
+	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S',
+	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
+	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
+	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
+	"TAT": 'Y', "TAC": 'Y', "TAA": '*', "TAG": '*',
+	"CAT": 'H', "CAC": 'H', "CAA": 'Q', "CAG": 'Q',
+	"AAT": 'N', "AAC": 'N', "AAA": 'K', "AAG": 'K',
+	"GAT": 'D', "GAC": 'D', "GAA": 'E', "GAG": 'E',
+	"TGT": 'C', "TGC": 'C', "TGA": '*', "TGG": 'W',
+	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R',
+	"AGT": 'S', "AGC": 'S', "AGA": 'R', "AGG": 'R',
+	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
+}
+
+// translate returns the amino acid for each complete codon of seq read in
+// the given frame (1, 2 or 3). Case is ignored and U is read as T; codons
+// containing anything other than A/C/G/T translate to 'X'.
+func translate(seq []rune, frame int) []rune {
+	var protein []rune
+	for i := frame - 1; i+3 <= len(seq); i += 3 {
+		codon := strings.Map(func(r rune) rune {
+			r = unicode.ToUpper(r)
+			if r == 'U' {
+				return 'T'
+			}
+			return r
+		}, string(seq[i:i+3]))
+		aa, ok := codonTable[codon]
+		if !ok {
+			aa = 'X'
+		}
+		protein = append(protein, aa)
+	}
+	return protein
+}
+
+// translationLine lays protein out under the filled bases [lo, hi),
+// placing each amino acid beneath the middle base of its codon once the
+// whole codon is shown.
+func translationLine(protein []rune, frame, lo, hi int) string {
+	line := []rune(strings.Repeat(" ", hi))
+	for i, aa := range protein {
+		start := frame - 1 + i*3
+		if start+3 > hi {
+			break
+		}
+		if start >= lo {
+			line[start+1] = aa
+		}
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// mismatchMarks compares the expected complement with the actual bottom
+// strand, position by position and ignoring case, returning '^' where they
+// differ and ' ' where they pair.
+func mismatchMarks(expected, actual []rune) []rune {
+	marks := make([]rune, len(actual))
+	for i := range actual {
+		marks[i] = ' '
+		if i >= len(expected) || unicode.ToUpper(expected[i]) != unicode.ToUpper(actual[i]) {
+			marks[i] = '^'
+		}
+	}
+	return marks
+}
+
+// pairTicks returns '|' where bottom[i] is the complement of top[i]
+// under pairs, ignoring case, and ' ' elsewhere. Gaps and N never pair.
+func pairTicks(top, bottom []rune, pairs map[rune]rune) []rune {
+	expected := generateComplement(top, pairs)
+	ticks := make([]rune, len(bottom))
+	for i := range bottom {
+		ticks[i] = ' '
+		switch unicode.ToUpper(top[i]) {
+		case '-', 'N':
+			continue
+		}
+		if unicode.ToUpper(expected[i]) == unicode.ToUpper(bottom[i]) {
+			ticks[i] = '|'
+		}
+	}
+	return ticks
+}
+
+// SanitizeSequence cleans up a pasted sequence so it renders at the right
+// width. It removes:
+//   - whitespace and line breaks,
+//   - FASTA description lines (those starting with '>'),
+//   - digits, such as coordinates from a GenBank listing, except a '5' or
+//     '3' end marker at the very start or end,
+//   - prime marks (' and ′) that accompany those end markers.
+//
+// IUPAC nucleotide letters (ACGTU RYSWKM BDHV N, either case) and '-' gaps
+// survive unchanged. Any other character is replaced with 'N' so that it
+// stands out in the bar rather than silently shifting the bases.
+func SanitizeSequence(s string) string {
+	return sanitize(s, nil)
+}
+
+// SanitizeSequenceReport is SanitizeSequence, also returning the characters
+// it replaced with 'N' because they are not bases, in the order they
+// appear, so a caller can warn about them. invalid is nil when every
+// character was a base or was dropped as formatting.
+func SanitizeSequenceReport(s string) (clean string, invalid []rune) {
+	return sanitizeReport(s, nil)
+}
+
+// sanitize implements SanitizeSequence, additionally keeping any base that
+// is a key of pairs (a WithComplementMap table).
+func sanitize(s string, pairs map[rune]rune) string {
+	clean, _ := sanitizeReport(s, pairs)
+	return clean
+}
+
+// sanitizeReport is sanitize, also returning the characters that were
+// replaced with 'N' because they are not bases, in order.
+func sanitizeReport(s string, pairs map[rune]rune) (clean string, replaced []rune) {
+	var kept []rune
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		for _, r := range line {
+			switch {
+			case unicode.IsSpace(r), r == '\'', r == '′':
+				continue
+			case unicode.IsDigit(r) && r != '5' && r != '3':
+				continue
+			}
+			kept = append(kept, r)
+		}
+	}
+
+	out := make([]rune, 0, len(kept))
+	for i, r := range kept {
+		switch {
+		case r == '5' || r == '3':
+			if i != 0 && i != len(kept)-1 {
+				continue // an interior 5 or 3 is a coordinate, not an end marker
+			}
+		case r == '-' || strings.ContainsRune(iupacBases, unicode.ToUpper(r)):
+		case pairs[r] != 0 || pairs[unicode.ToUpper(r)] != 0:
+		default:
+			replaced = append(replaced, r)
+			r = 'N'
+		}
+		out = append(out, r)
+	}
+	return string(out), replaced
+}
+
+// iupacBases lists every IUPAC nucleotide code, in uppercase.
+const iupacBases = "ACGTURYSWKMBDHVN"
+
+// RandomSequence returns length random bases drawn from A, C, G and T, for
+// demos and tests. The same seed always gives the same sequence. A length
+// that is not positive gives "".
+func RandomSequence(length int, seed int64) string {
+	if length <= 0 {
+		return ""
+	}
+	rng := rand.New(rand.NewSource(seed))
+	seq := make([]byte, length)
+	for i := range seq {
+		seq[i] = "ACGT"[rng.Intn(4)]
+	}
+	return string(seq)
+}
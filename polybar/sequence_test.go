@@ -0,0 +1,140 @@
+package polybar
+
+import (
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		seq   string
+		frame int
+		want  string
+	}{
+		{"ATGGCCTAA", 1, "MA*"},
+		{"ATGGCCTAA", 2, "WP"},
+		{"ATGGCCTAA", 3, "GL"},
+		{"ATGTGATAG", 1, "M**"},
+		{"AUGUAA", 1, "M*"},
+		{"ATGNNNGC", 1, "MX"},
+	}
+	for _, tt := range tests {
+		if got := string(translate([]rune(tt.seq), tt.frame)); got != tt.want {
+			t.Errorf("translate(%s, %d) = %s, want %s", tt.seq, tt.frame, got, tt.want)
+		}
+	}
+}
+
+func TestTranslationLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame int
+		lo    int
+		hi    int
+		want  string
+	}{
+		{"frame 1", 1, 0, 9, " M  A  *"},
+		{"frame 2", 2, 0, 9, "  W  P"},
+		{"frame 3", 3, 0, 9, "   G  L"},
+		{"codon not yet complete", 1, 0, 5, " M"},
+		{"nothing shown", 1, 0, 2, ""},
+		{"codon starting before lo", 1, 2, 9, "    A  *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protein := translate([]rune("ATGGCCTAA"), tt.frame)
+			if got := translationLine(protein, tt.frame, tt.lo, tt.hi); got != tt.want {
+				t.Errorf("translationLine = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Each amino acid sits under the middle base of its codon.
+	pb := New("ATGGCCTAA", "", WithForceTTY(false), WithTranslation(1))
+	pb.SetOutput(io.Discard)
+	pb.Start(9)
+	pb.SetProgress(9)
+	lines := strings.Split(pb.Frame(), "\n")
+	top, protein := []rune(lines[1]), []rune(lines[3])
+	for i, codon := range []string{"ATG", "GCC", "TAA"} {
+		mid := 2 + 3*i + 1 // past the "--" prefix
+		if string(top[mid-1:mid+2]) != codon {
+			t.Errorf("codon %d = %q, want %q", i, string(top[mid-1:mid+2]), codon)
+		}
+		if want := []rune("MA*")[i]; protein[mid] != want {
+			t.Errorf("amino acid under codon %s = %q, want %q", codon, protein[mid], want)
+		}
+	}
+}
+
+func TestMeltingTemp(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  string
+		want float64
+	}{
+		{"Wallace rule", "ATGC", 12},
+		{"Wallace rule at wallaceMax", strings.Repeat("AATGGC", 5), 90},
+		{"GC formula past wallaceMax", strings.Repeat("AATGGC", 5) + "A", 64.9 + 41*(15-16.4)/31},
+		{"GC formula", strings.Repeat("GC", 20), 64.9 + 41*(40-16.4)/40},
+		{"W and S count", "WWSS", 12},
+		{"gaps and N left out", "A-T-NNG", 8},
+		{"lowercase", "atgc", 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meltingTemp([]rune(tt.seq)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("meltingTemp(%s) = %v, want %v", tt.seq, got, tt.want)
+			}
+		})
+	}
+
+	if got := meltingTemp([]rune("NN--")); !math.IsNaN(got) {
+		t.Errorf("meltingTemp with no countable bases = %v, want NaN", got)
+	}
+	pb := New("NNNN", "", WithQuiet(true), WithShowTm())
+	pb.Start(1)
+	if frame := pb.Frame(); !strings.HasSuffix(frame, " Tm=n/a") {
+		t.Errorf("status line of %q, want Tm=n/a", frame)
+	}
+}
+
+func TestSanitizeSequenceDigits(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"5'-ACGT-3'", "5-ACGT-3"},
+		{"1 acgtacgt 9", "acgtacgt"},
+		{"AC5GT", "ACGT"},
+		{"AC3G5T", "ACGT"},
+		{"61 ACGT 120", "ACGT"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeSequence(tt.in); got != tt.want {
+			t.Errorf("SanitizeSequence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeSequenceReport(t *testing.T) {
+	tests := []struct {
+		in      string
+		clean   string
+		invalid []rune
+	}{
+		{"ACGT", "ACGT", nil},
+		{">seq1\nAC GT\n", "ACGT", nil},
+		{"5'-ACGT-3'", "5-ACGT-3", nil},
+		{"AC!GZ", "ACNGN", []rune{'!', 'Z'}},
+		{"PLEQ", "NNNN", []rune{'P', 'L', 'E', 'Q'}},
+	}
+	for _, tt := range tests {
+		clean, invalid := SanitizeSequenceReport(tt.in)
+		if clean != tt.clean || !slices.Equal(invalid, tt.invalid) {
+			t.Errorf("SanitizeSequenceReport(%q) = %q, %q; want %q, %q", tt.in, clean, invalid, tt.clean, tt.invalid)
+		}
+	}
+}
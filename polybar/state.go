@@ -0,0 +1,82 @@
+package polybar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// State is a snapshot of a bar's progress and what it displays, for
+// checkpointing a long job to disk (it marshals to JSON) and rebuilding an
+// equivalent bar with Restore after a restart. For a StartWeighted or
+// StartBytes run, Completed and Total are the internal step scale and the
+// weights or byte counts are kept alongside.
+type State struct {
+	Completed   int     `json:"completed"`
+	Total       int     `json:"total"`
+	TopStrand   string  `json:"top_strand"`
+	Header      string  `json:"header"`
+	Width       int     `json:"width"`
+	WeightDone  float64 `json:"weight_done,omitempty"`
+	WeightTotal float64 `json:"weight_total,omitempty"`
+	BytesDone   int64   `json:"bytes_done,omitempty"`
+	BytesTotal  int64   `json:"bytes_total,omitempty"`
+}
+
+// State returns the bar's current progress, sequence, header and width.
+func (pb *ProgressBar) State() State {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return State{
+		Completed:   pb.completed,
+		Total:       pb.total,
+		TopStrand:   pb.sequence,
+		Header:      pb.headerLine,
+		Width:       pb.width,
+		WeightDone:  pb.weightDone,
+		WeightTotal: pb.weightTotal,
+		BytesDone:   pb.bytesDone,
+		BytesTotal:  pb.bytesTotal,
+	}
+}
+
+// Restore builds a bar from a State saved by State and draws it at the
+// saved progress, as if Start had been called and the run had got that
+// far. opts are applied as in New (use WithOutput to choose where that
+// first frame goes); the saved width wins over WithWidth. A weighted or
+// byte run carries on with AddWeight or AddBytes. Timing (elapsed, ETA,
+// rate) starts again from the moment of Restore. It returns an error if
+// the saved total is not positive.
+func Restore(s State, opts ...Option) (*ProgressBar, error) {
+	if s.Total <= 0 {
+		return nil, fmt.Errorf("polybar: total must be positive, got %d", s.Total)
+	}
+	opts = append(opts[:len(opts):len(opts)], WithWidth(s.Width))
+	pb := New(s.TopStrand, s.Header, opts...)
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.total = s.Total
+	pb.completed = min(max(s.Completed, 0), s.Total)
+	switch {
+	case s.WeightTotal > 0:
+		pb.total = weightSteps
+		pb.weightTotal = s.WeightTotal
+		pb.weightDone = min(max(s.WeightDone, 0), s.WeightTotal)
+		pb.completed = int(math.Round(pb.weightDone / pb.weightTotal * weightSteps))
+	case s.BytesTotal > 0:
+		pb.total = weightSteps
+		pb.bytesTotal = s.BytesTotal
+		pb.bytesDone = min(max(s.BytesDone, 0), s.BytesTotal)
+		pb.completed = int(math.Round(float64(pb.bytesDone) / float64(pb.bytesTotal) * weightSteps))
+	}
+	pb.started = time.Now()
+	pb.logged = -1
+	if pb.milestoneStep > 0 {
+		// Milestones up to the checkpoint were logged before it was saved.
+		pb.milestone = int(math.Floor(pb.percent()/pb.milestoneStep + 1e-9))
+	}
+	pb.progressed()
+	pb.render()
+	return pb, nil
+}
@@ -0,0 +1,55 @@
+package polybar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRestoreKeepsWeightsAndBytes(t *testing.T) {
+	weighted := New("ACGT", "", WithQuiet(true))
+	weighted.StartWeighted(6.25)
+	weighted.AddWeight(2.5)
+
+	byBytes := New("ACGT", "", WithQuiet(true))
+	byBytes.StartBytes(3 << 30)
+	byBytes.AddBytes(1 << 30)
+
+	tests := []struct {
+		name string
+		pb   *ProgressBar
+		want string
+	}{
+		{"weighted", weighted, "40.0% (2.5/6.25)"},
+		{"bytes", byBytes, "33.3% (1.0 GB/3.0 GB)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			pb, err := Restore(tt.pb.State(), WithOutput(&out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if frame := pb.Frame(); !strings.Contains(frame, tt.want) {
+				t.Errorf("restored frame %q does not contain %q", frame, tt.want)
+			}
+			if !strings.Contains(out.String(), "Progress: ") {
+				t.Errorf("first draw did not go to WithOutput, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestRestoreLogsPercentOffTerminal(t *testing.T) {
+	for _, completed := range []int{0, 3} {
+		var out bytes.Buffer
+		s := State{Completed: completed, Total: 10, TopStrand: "ACGT", Header: "align", Width: 4}
+		if _, err := Restore(s, WithOutput(&out), WithForceTTY(false)); err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("align: %d%%\n", completed*10); out.String() != want {
+			t.Errorf("Restore at %d/10 wrote %q, want %q", completed, out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package polybar
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// columns returns the width of the output terminal when WithAutoWidth is
+// set, or 0 when lines need not be fitted (auto width off, output not a
+// terminal, or its size unknown). It is queried on every frame so a
+// resized window is picked up. Callers must hold pb.mu.
+func (pb *ProgressBar) columns() int {
+	if !pb.autoWidth {
+		return 0
+	}
+	f, ok := pb.out.(*os.File)
+	if !ok {
+		return 0
+	}
+	cols, _, err := term.GetSize(int(f.Fd()))
+	if err != nil || cols <= 0 {
+		return 0
+	}
+	return cols
+}
+
+// viewport returns which span of the strands to show: the whole width
+// normally, or, when it is wider than WithMaxWidth allows or than fits on
+// the terminal beside the two-column prefix and the arrow, the widest span
+// that does, slid along so the growing end of the fill (hi) stays in view.
+// Callers must hold pb.mu.
+func (pb *ProgressBar) viewport(hi int) (off, span int) {
+	span = pb.width
+	if pb.maxWidth > 0 {
+		span = min(span, pb.maxWidth)
+	}
+	if cols := pb.columns(); cols > 0 {
+		span = min(span, cols-2-utf8.RuneCountInString(pb.arrow))
+	}
+	if span >= pb.width {
+		return 0, pb.width
+	}
+	span = max(span, 1)
+	off = min(max(hi-span, 0), pb.width-span)
+	return off, span
+}
+
+// clip shortens s to at most cols visible runes; cols == 0 leaves s
+// unchanged. If an ANSI sequence may have been cut, the colors are reset.
+func clip(s string, cols int) string {
+	if cols == 0 || utf8.RuneCountInString(s) <= cols {
+		return s
+	}
+	clipped := string([]rune(s)[:cols])
+	if strings.Contains(clipped, "\033[") {
+		clipped += ansiReset
+	}
+	return clipped
+}
@@ -0,0 +1,40 @@
+package polybar
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// count formats a step count for the status line: as a byte size under
+// WithHumanizeBytes, otherwise as a plain integer. Callers must hold pb.mu.
+func (pb *ProgressBar) count(n int) string {
+	if pb.humanize {
+		return humanBytes(int64(n))
+	}
+	return strconv.Itoa(n)
+}
+
+// unitSuffix returns the WithUnit label with a leading space, or "".
+// Callers must hold pb.mu.
+func (pb *ProgressBar) unitSuffix() string {
+	if pb.unit == "" {
+		return ""
+	}
+	return " " + pb.unit
+}
+
+// humanBytes formats n bytes with a binary-scaled unit, e.g. "512 B",
+// "1.5 KB", "3.2 GB".
+func humanBytes(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 && n > -1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	v := float64(n) / 1024
+	i := 0
+	for (v >= 1024 || v <= -1024) && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %cB", v, units[i])
+}
@@ -0,0 +1,53 @@
+package polybar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// minNucleotideFraction is the share of bases Validate expects to be
+// plain A/C/G/T/U before it suspects the input is not DNA.
+const minNucleotideFraction = 0.8
+
+// Validate reports whether the sequence the bar was built from looks like
+// DNA. Anything SanitizeSequence does not recognise is drawn as N, so a
+// protein pasted by mistake silently becomes a bar full of Ns; Validate
+// catches that by returning an error when fewer than 80% of the bases
+// (ignoring gaps) are A, C, G, T or U, or a base from a WithComplementMap
+// table. The bottom strand of a NewDuplex bar is checked the same way.
+// The bar is usable either way; the error is only a warning.
+func (pb *ProgressBar) Validate() error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if f := nucleotideFraction(pb.sequence, pb.pairs); f < minNucleotideFraction {
+		return fmt.Errorf("polybar: only %.0f%% of the sequence is A/C/G/T/U; it may not be DNA", f*100)
+	}
+	if pb.duplex {
+		if f := nucleotideFraction(pb.bottomSeq, pb.pairs); f < minNucleotideFraction {
+			return fmt.Errorf("polybar: only %.0f%% of the bottom strand is A/C/G/T/U; it may not be DNA", f*100)
+		}
+	}
+	return nil
+}
+
+// nucleotideFraction returns the share of seq, gaps and end markers aside,
+// made up of A/C/G/T/U in either case or keys of pairs. A sequence with no
+// such bases to count returns 1.
+func nucleotideFraction(seq string, pairs map[rune]rune) float64 {
+	var known, counted int
+	for _, r := range seq {
+		switch r {
+		case '-', '5', '3':
+			continue
+		}
+		counted++
+		if strings.ContainsRune("ACGTU", unicode.ToUpper(r)) || pairs[r] != 0 || pairs[unicode.ToUpper(r)] != 0 {
+			known++
+		}
+	}
+	if counted == 0 {
+		return 1
+	}
+	return float64(known) / float64(counted)
+}
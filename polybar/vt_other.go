@@ -0,0 +1,9 @@
+//go:build !windows
+
+package polybar
+
+import "io"
+
+// enableVirtualTerminal is a no-op: non-Windows terminals already
+// understand ANSI escapes.
+func enableVirtualTerminal(io.Writer) {}
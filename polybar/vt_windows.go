@@ -0,0 +1,26 @@
+//go:build windows
+
+package polybar
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal turns on ANSI escape processing for w's console so
+// the cursor-movement and color codes aren't printed literally by older
+// Windows terminals. Errors are ignored: the bar still works, just uglier.
+func enableVirtualTerminal(w io.Writer) {
+	f, ok := w.(*os.File)
+	if !ok {
+		return
+	}
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return
+	}
+	_ = windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+}
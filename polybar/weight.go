@@ -0,0 +1,58 @@
+package polybar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// weightSteps is how many steps a weighted or StartBytes run is divided
+// into; the accumulated weight or byte count is rounded to the nearest
+// step for drawing.
+const weightSteps = 10000
+
+// StartWeighted starts a bar whose work items have different sizes, e.g.
+// files of different lengths. Progress is reported with AddWeight
+// (Update, Add and SetProgress are ignored) and the percentage follows
+// the accumulated weight rather than the item count, so the bar moves
+// smoothly. The status line shows the weights, e.g. "40.0% (2.5/6.25)".
+// It returns an error, and draws nothing, if totalWeight is not positive.
+func (pb *ProgressBar) StartWeighted(totalWeight float64) error {
+	if !(totalWeight > 0) || math.IsInf(totalWeight, 1) {
+		return fmt.Errorf("polybar: total weight must be positive, got %g", totalWeight)
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if err := pb.restart(weightSteps); err != nil {
+		return err
+	}
+	pb.weightTotal = totalWeight
+	pb.weightDone = 0
+	pb.progressed()
+	pb.render()
+	return nil
+}
+
+// AddWeight adds w to the weight completed and refreshes. The total is
+// clamped to [0, totalWeight]. It does nothing unless the bar was started
+// with StartWeighted.
+func (pb *ProgressBar) AddWeight(w float64) {
+	pb.mu.Lock()
+	if pb.weightTotal == 0 || math.IsNaN(w) {
+		pb.mu.Unlock()
+		return
+	}
+	pb.weightDone = min(max(pb.weightDone+w, 0), pb.weightTotal)
+	pb.completed = int(math.Round(pb.weightDone / pb.weightTotal * weightSteps))
+	pb.progressed()
+	pb.render()
+	done := pb.completion()
+	pb.mu.Unlock()
+	done()
+}
+
+// weightText formats a weight for the status line with no exponent and no
+// trailing zeros, e.g. "2500000" or "2.5".
+func weightText(w float64) string {
+	return strconv.FormatFloat(w, 'f', -1, 64)
+}
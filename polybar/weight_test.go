@@ -0,0 +1,47 @@
+package polybar
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWeightedAndByteRunsReportTheirCounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(pb *ProgressBar)
+		milestone string
+		event     Event
+	}{
+		{"weighted", func(pb *ProgressBar) {
+			pb.StartWeighted(6.25)
+			pb.AddWeight(2.5)
+		}, "40% (2.5/6.25)", Event{Completed: 4000, Total: weightSteps, Percent: 40, WeightDone: 2.5, WeightTotal: 6.25}},
+		{"bytes", func(pb *ProgressBar) {
+			pb.StartBytes(4096)
+			pb.AddBytes(1024)
+		}, "25% (1.0 KB/4.0 KB)", Event{Completed: 2500, Total: weightSteps, Percent: 25, BytesDone: 1024, BytesTotal: 4096}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var milestones, events bytes.Buffer
+			pb := New("ACGT", "", WithQuiet(true), WithMilestoneWriter(&milestones, 5), WithEventWriter(&events))
+			tt.run(pb)
+
+			lines := strings.Split(strings.TrimSpace(milestones.String()), "\n")
+			if got := lines[len(lines)-1]; got != tt.milestone {
+				t.Errorf("last milestone = %q, want %q", got, tt.milestone)
+			}
+			records := strings.Split(strings.TrimSpace(events.String()), "\n")
+			var got Event
+			if err := json.Unmarshal([]byte(records[len(records)-1]), &got); err != nil {
+				t.Fatal(err)
+			}
+			got.ElapsedMS = 0
+			if got != tt.event {
+				t.Errorf("last event = %+v, want %+v", got, tt.event)
+			}
+		})
+	}
+}